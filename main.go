@@ -12,6 +12,8 @@ import (
 	"time"
 
 	solanaswapgo "github.com/P-HOW/solana-swap-decode/solanaswap-go"
+	"github.com/P-HOW/solana-swap-decode/solanaswap-go/idl"
+	"github.com/P-HOW/solana-swap-decode/spltoken/bondingcurve"
 	holder "github.com/P-HOW/solana-swap-decode/spltoken/holder"
 	pricepkg "github.com/P-HOW/solana-swap-decode/spltoken/price"
 
@@ -26,6 +28,12 @@ type parseReq struct {
 type parseResp struct {
 	Transaction interface{} `json:"transaction"`
 	SwapInfo    interface{} `json:"swapInfo"`
+	Swaps       interface{} `json:"swaps,omitempty"`
+	FailedSwap  interface{} `json:"failedSwap,omitempty"`
+	Launches    interface{} `json:"launches,omitempty"`
+	Pools       interface{} `json:"pools,omitempty"`
+	Liquidity   interface{} `json:"liquidity,omitempty"`
+	Decoded     interface{} `json:"decoded,omitempty"`
 }
 
 type holdersReq struct {
@@ -70,6 +78,17 @@ func main() {
 	// Shared Solana RPC client (safe for concurrent use)
 	client := rpc.New(rpcURL)
 
+	// Optional Anchor IDLs (*.json) for /parse "decoded" output
+	var idlPrograms []*idl.Program
+	if dir := strings.TrimSpace(os.Getenv("IDL_DIR")); dir != "" {
+		progs, err := idl.LoadPrograms(dir)
+		if err != nil {
+			log.Fatalf("loading IDLs from %s: %v", dir, err)
+		}
+		idlPrograms = progs
+		log.Printf("loaded %d IDLs from %s", len(progs), dir)
+	}
+
 	// Health endpoint
 	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -195,6 +214,9 @@ func main() {
 			writeJSONMaybePretty(w, http.StatusUnprocessableEntity, apiError{Error: "parse_init_error", Details: err.Error()}, pretty)
 			return
 		}
+		for _, prog := range idlPrograms {
+			parser.RegisterIDL(prog)
+		}
 
 		transactionData, err := parser.ParseTransaction()
 		if err != nil {
@@ -208,9 +230,53 @@ func main() {
 			log.Printf("swap processing warning: %v", err)
 		}
 
+		// Per-instruction/per-trader breakdown (only useful when there is more than one)
+		var swaps interface{}
+		if all, err := parser.ProcessAllSwaps(transactionData); err == nil && len(all) > 1 {
+			swaps = all
+		}
+
+		// Failed transactions: report the attempted swap instead
+		var failed interface{}
+		if parser.IsFailed() {
+			if fs, err := parser.ParseFailedSwap(); err == nil {
+				failed = fs
+			}
+		}
+
+		// Token creations on launchpads (Pump.fun, LaunchLab, Meteora DBC)
+		var launches interface{}
+		if ls, err := parser.ParseLaunches(); err == nil && len(ls) > 0 {
+			launches = ls
+		}
+
+		// New pools and launchpad migrations
+		var pools interface{}
+		if ps, err := parser.ParsePoolCreations(); err == nil && len(ps) > 0 {
+			pools = ps
+		}
+
+		// LP deposits and withdrawals (ParseTransaction skips these)
+		var liquidity interface{}
+		if ls, err := parser.ParseLiquidity(); err == nil && len(ls) > 0 {
+			liquidity = ls
+		}
+
+		// Instructions of programs with a loaded IDL
+		var decoded interface{}
+		if ds := parser.DecodedInstructions(); len(ds) > 0 {
+			decoded = ds
+		}
+
 		writeJSONMaybePretty(w, http.StatusOK, parseResp{
 			Transaction: transactionData,
 			SwapInfo:    swapInfo, // may be nil
+			Swaps:       swaps,
+			FailedSwap:  failed,
+			Launches:    launches,
+			Pools:       pools,
+			Liquidity:   liquidity,
+			Decoded:     decoded,
 		}, pretty)
 	})
 
@@ -369,6 +435,86 @@ func main() {
 		}, pretty)
 	})
 
+	// ---- Bonding-curve progress (GET or POST) ----
+	type curveReq struct {
+		Mint  string `json:"mint"`
+		Limit int    `json:"limit,omitempty"` // recent txs to scan
+	}
+	type curveResp struct {
+		Mint                 string  `json:"mint"`
+		Signature            string  `json:"signature"`
+		Slot                 uint64  `json:"slot"`
+		BlockTime            int64   `json:"blockTime"`
+		VirtualSolReserves   uint64  `json:"virtualSolReserves"`
+		VirtualTokenReserves uint64  `json:"virtualTokenReserves"`
+		RealTokenReserves    uint64  `json:"realTokenReserves"`
+		PriceSOL             float64 `json:"priceSOL"`
+		MarketCapSOL         float64 `json:"marketCapSOL"`
+		CompletionPct        float64 `json:"completionPct"`
+		Complete             bool    `json:"complete"`
+		Completed            bool    `json:"completedInThisTx"`
+	}
+
+	http.HandleFunc("/bondingcurve", func(w http.ResponseWriter, r *http.Request) {
+		pretty := r.URL.Query().Get("pretty") == "1" || r.URL.Query().Get("pretty") == "true"
+
+		var req curveReq
+		switch r.Method {
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSONMaybePretty(w, http.StatusBadRequest, apiError{Error: "bad_request", Details: "invalid JSON body"}, pretty)
+				return
+			}
+		case http.MethodGet:
+			req.Mint = r.URL.Query().Get("mint")
+			if v := strings.TrimSpace(r.URL.Query().Get("limit")); v != "" {
+				if n, err := strconv.Atoi(v); err == nil {
+					req.Limit = n
+				}
+			}
+		default:
+			writeJSONMaybePretty(w, http.StatusMethodNotAllowed, apiError{Error: "method_not_allowed"}, pretty)
+			return
+		}
+
+		mintPK, err := solana.PublicKeyFromBase58(strings.TrimSpace(req.Mint))
+		if err != nil {
+			writeJSONMaybePretty(w, http.StatusBadRequest, apiError{Error: "bad_request", Details: "invalid mint (base58)"}, pretty)
+			return
+		}
+		// each scanned transaction is one getTransaction call
+		if req.Limit > bondingcurve.MaxScanLimit {
+			req.Limit = bondingcurve.MaxScanLimit
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), rpcTimeout)
+		defer cancel()
+
+		pr, err := bondingcurve.LatestProgress(ctx, client, mintPK, req.Limit)
+		if errors.Is(err, bondingcurve.ErrNoTrade) {
+			writeJSONMaybePretty(w, http.StatusNotFound, apiError{Error: "not_found", Details: err.Error()}, pretty)
+			return
+		}
+		if err != nil {
+			writeJSONMaybePretty(w, http.StatusBadGateway, apiError{Error: "rpc_error", Details: err.Error()}, pretty)
+			return
+		}
+		writeJSONMaybePretty(w, http.StatusOK, curveResp{
+			Mint:                 mintPK.String(),
+			Signature:            pr.Signature.String(),
+			Slot:                 pr.Slot,
+			BlockTime:            pr.BlockTime,
+			VirtualSolReserves:   pr.VirtualSolReserves,
+			VirtualTokenReserves: pr.VirtualTokenReserves,
+			RealTokenReserves:    pr.RealTokenReserves,
+			PriceSOL:             pr.PriceSOL,
+			MarketCapSOL:         pr.MarketCapSOL,
+			CompletionPct:        pr.CompletionPct,
+			Complete:             pr.Complete,
+			Completed:            pr.Completed,
+		}, pretty)
+	})
+
 	// HTTP server settings
 	addr := ":8080"
 	srv := &http.Server{
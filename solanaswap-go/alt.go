@@ -0,0 +1,178 @@
+package solanaswapgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ALTResolver returns the addresses stored in an address lookup table.
+type ALTResolver interface {
+	ResolveLookupTable(ctx context.Context, table solana.PublicKey) (solana.PublicKeySlice, error)
+}
+
+// StaticALTResolver is an in-memory ALTResolver, e.g. for tests or tables
+// fetched ahead of time.
+type StaticALTResolver map[solana.PublicKey]solana.PublicKeySlice
+
+func (r StaticALTResolver) ResolveLookupTable(_ context.Context, table solana.PublicKey) (solana.PublicKeySlice, error) {
+	addrs, ok := r[table]
+	if !ok {
+		return nil, fmt.Errorf("lookup table %s not found", table)
+	}
+	return addrs, nil
+}
+
+// RPCALTResolver fetches lookup tables over RPC and caches them. Tables are
+// append-only, so a cached table stays valid for the indexes it already has;
+// Invalidate drops one that has since been extended. Resolving a message does
+// so itself when an index is past the cached table.
+type RPCALTResolver struct {
+	client RPCCaller
+	mu     sync.Mutex
+	tables map[solana.PublicKey]solana.PublicKeySlice
+}
+
+// NewRPCALTResolver returns a resolver backed by client, typically an *rpc.Client.
+func NewRPCALTResolver(client RPCCaller) *RPCALTResolver {
+	return &RPCALTResolver{client: client, tables: make(map[solana.PublicKey]solana.PublicKeySlice)}
+}
+
+func (r *RPCALTResolver) ResolveLookupTable(ctx context.Context, table solana.PublicKey) (solana.PublicKeySlice, error) {
+	r.mu.Lock()
+	addrs, ok := r.tables[table]
+	r.mu.Unlock()
+	if ok {
+		return addrs, nil
+	}
+
+	accounts, err := getMultipleAccounts(ctx, r.client, []solana.PublicKey{table})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lookup table %s: %w", table, err)
+	}
+	acc := accounts[0]
+	if acc == nil {
+		return nil, fmt.Errorf("lookup table %s not found", table)
+	}
+	if !acc.Owner.Equals(ADDRESS_LOOKUP_TABLE_PROGRAM_ID) {
+		return nil, fmt.Errorf("account %s is not a lookup table", table)
+	}
+	addrs, err = decodeLookupTableAddresses(acc.Data)
+	if err != nil {
+		return nil, fmt.Errorf("lookup table %s: %w", table, err)
+	}
+
+	r.mu.Lock()
+	r.tables[table] = addrs
+	r.mu.Unlock()
+	return addrs, nil
+}
+
+// Invalidate drops table from the cache so the next lookup refetches it.
+func (r *RPCALTResolver) Invalidate(table solana.PublicKey) {
+	r.mu.Lock()
+	delete(r.tables, table)
+	r.mu.Unlock()
+}
+
+// lookupTableMetaSize is the fixed header of a lookup table account
+// (LOOKUP_TABLE_META_SIZE); the addresses follow it.
+const lookupTableMetaSize = 56
+
+func decodeLookupTableAddresses(data []byte) (solana.PublicKeySlice, error) {
+	if len(data) < lookupTableMetaSize || (len(data)-lookupTableMetaSize)%32 != 0 {
+		return nil, fmt.Errorf("invalid lookup table data length %d", len(data))
+	}
+	data = data[lookupTableMetaSize:]
+	addrs := make(solana.PublicKeySlice, 0, len(data)/32)
+	for i := 0; i < len(data); i += 32 {
+		addrs = append(addrs, solana.PublicKeyFromBytes(data[i:i+32]))
+	}
+	return addrs, nil
+}
+
+// ResolveAddressLookups fills the keys a v0 transaction loads from lookup
+// tables when the meta doesn't carry them (raw, simulated or mempool
+// transactions): all writable addresses in lookup order, then all read-only
+// ones, as the runtime does. It is a no-op when the meta already has loaded
+// addresses or the message has no lookups.
+func (p *Parser) ResolveAddressLookups(ctx context.Context, r ALTResolver) error {
+	lookups := p.txInfo.Message.AddressTableLookups
+	loaded := p.txMeta.LoadedAddresses
+	if len(lookups) == 0 || len(loaded.Writable)+len(loaded.ReadOnly) > 0 {
+		return nil
+	}
+
+	writable, readonly, err := resolveLookups(ctx, lookups, r)
+	if err != nil {
+		return err
+	}
+
+	meta := *p.txMeta
+	meta.LoadedAddresses = rpc.LoadedAddresses{Writable: writable, ReadOnly: readonly}
+	p.txMeta = &meta
+
+	keys := make(solana.PublicKeySlice, 0, len(p.txInfo.Message.AccountKeys)+len(writable)+len(readonly))
+	keys = append(keys, p.txInfo.Message.AccountKeys...)
+	keys = append(keys, writable...)
+	p.allAccountKeys = append(keys, readonly...)
+	p.instructionTree = nil
+	p.trader = nil
+
+	if err := p.extractSPLTokenInfo(); err != nil {
+		return fmt.Errorf("failed to extract SPL Token Addresses: %w", err)
+	}
+	if err := p.extractSPLDecimals(); err != nil {
+		return fmt.Errorf("failed to extract SPL decimals: %w", err)
+	}
+	p.extractTokenOwners()
+	return nil
+}
+
+// resolveLookups returns the addresses a message loads: all writable ones in
+// lookup order, then all read-only ones.
+func resolveLookups(ctx context.Context, lookups solana.MessageAddressTableLookupSlice, r ALTResolver) (writable, readonly solana.PublicKeySlice, err error) {
+	for _, l := range lookups {
+		addrs, err := lookupTableAddresses(ctx, l, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, idx := range l.WritableIndexes {
+			if int(idx) >= len(addrs) {
+				return nil, nil, fmt.Errorf("index %d out of range for lookup table %s (%d addresses)", idx, l.AccountKey, len(addrs))
+			}
+			writable = append(writable, addrs[idx])
+		}
+		for _, idx := range l.ReadonlyIndexes {
+			if int(idx) >= len(addrs) {
+				return nil, nil, fmt.Errorf("index %d out of range for lookup table %s (%d addresses)", idx, l.AccountKey, len(addrs))
+			}
+			readonly = append(readonly, addrs[idx])
+		}
+	}
+	return writable, readonly, nil
+}
+
+// lookupTableAddresses resolves the table of l. A table cached by an
+// RPCALTResolver may predate an extension the message indexes into; it is
+// refetched once before the index is reported out of range.
+func lookupTableAddresses(ctx context.Context, l solana.MessageAddressTableLookup, r ALTResolver) (solana.PublicKeySlice, error) {
+	addrs, err := r.ResolveLookupTable(ctx, l.AccountKey)
+	if err != nil {
+		return nil, err
+	}
+	rr, ok := r.(*RPCALTResolver)
+	if !ok {
+		return addrs, nil
+	}
+	for _, idx := range append(append([]uint8{}, l.WritableIndexes...), l.ReadonlyIndexes...) {
+		if int(idx) >= len(addrs) {
+			rr.Invalidate(l.AccountKey)
+			return r.ResolveLookupTable(ctx, l.AccountKey)
+		}
+	}
+	return addrs, nil
+}
@@ -0,0 +1,141 @@
+package solanaswapgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// newRawMoonshotParser builds an unsent v0 Moonshot trade (no meta) whose
+// accounts 1-10 come from two lookup tables.
+func newRawMoonshotParser(t *testing.T) *Parser {
+	t.Helper()
+	data := append(MOONSHOT_BUY_INSTRUCTION[:], make([]byte, 25)...)
+	tx := &solana.Transaction{
+		Signatures: []solana.Signature{{1}},
+		Message: solana.Message{
+			Header:      solana.MessageHeader{NumRequiredSignatures: 1},
+			AccountKeys: []solana.PublicKey{testPayer, MOONSHOT_PROGRAM_ID},
+			Instructions: []solana.CompiledInstruction{{
+				ProgramIDIndex: 1,
+				Accounts:       []uint16{0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+				Data:           data,
+			}},
+			AddressTableLookups: []solana.MessageAddressTableLookup{
+				{AccountKey: testKey(100), WritableIndexes: []uint8{0, 1, 2}, ReadonlyIndexes: []uint8{3, 4, 5}},
+				{AccountKey: testKey(101), WritableIndexes: []uint8{1, 0}, ReadonlyIndexes: []uint8{2, 3}},
+			},
+		},
+	}
+	p, err := NewTransactionParserFromTransaction(tx, nil)
+	if err != nil {
+		t.Fatalf("NewTransactionParserFromTransaction: %v", err)
+	}
+	return p
+}
+
+var testLookupTables = StaticALTResolver{
+	testKey(100): {testKey(10), testKey(11), testKey(12), testKey(13), testKey(14), testKey(15)},
+	testKey(101): {testKey(20), testKey(21), testKey(22), testKey(23)},
+}
+
+func TestResolveAddressLookups(t *testing.T) {
+	p := newRawMoonshotParser(t)
+	if !p.isMoonshotTrade(p.txInfo.Message.Instructions[0]) {
+		t.Error("static program key not recognized")
+	}
+	if err := p.ResolveAddressLookups(context.Background(), testLookupTables); err != nil {
+		t.Fatalf("ResolveAddressLookups: %v", err)
+	}
+
+	// writable from both tables first, then read-only
+	want := []solana.PublicKey{
+		testPayer, MOONSHOT_PROGRAM_ID,
+		testKey(10), testKey(11), testKey(12), testKey(21), testKey(20),
+		testKey(13), testKey(14), testKey(15), testKey(22), testKey(23),
+	}
+	if len(p.allAccountKeys) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(p.allAccountKeys), len(want))
+	}
+	for i, k := range want {
+		if !p.allAccountKeys[i].Equals(k) {
+			t.Errorf("key %d = %s, want %s", i, p.allAccountKeys[i], k)
+		}
+	}
+	if n := len(p.txMeta.LoadedAddresses.Writable); n != 5 {
+		t.Errorf("loaded writable = %d", n)
+	}
+
+	// The Moonshot mint (account 6) is a loaded address.
+	mint := p.allAccountKeys[p.txInfo.Message.Instructions[0].Accounts[6]]
+	if !mint.Equals(testKey(13)) {
+		t.Errorf("moonshot mint = %s", mint)
+	}
+}
+
+func TestResolveAddressLookups_Errors(t *testing.T) {
+	p := newRawMoonshotParser(t)
+	short := StaticALTResolver{testKey(100): testLookupTables[testKey(100)][:2]}
+	if err := p.ResolveAddressLookups(context.Background(), short); err == nil {
+		t.Error("out-of-range index accepted")
+	}
+	if len(p.allAccountKeys) != 2 {
+		t.Errorf("keys changed on error: %d", len(p.allAccountKeys))
+	}
+
+	// Loaded addresses reported by the node win over the resolver.
+	p = newTestParser(t, []solana.PublicKey{testPayer}, nil, &rpc.TransactionMeta{
+		LoadedAddresses: rpc.LoadedAddresses{ReadOnly: []solana.PublicKey{testKey(9)}},
+	})
+	p.txInfo.Message.AddressTableLookups = []solana.MessageAddressTableLookup{{AccountKey: testKey(100), ReadonlyIndexes: []uint8{0}}}
+	if err := p.ResolveAddressLookups(context.Background(), StaticALTResolver{}); err != nil || !p.allAccountKeys[1].Equals(testKey(9)) {
+		t.Errorf("meta addresses replaced: %v, %v", err, p.allAccountKeys)
+	}
+}
+
+func TestDecodeLookupTableAddresses(t *testing.T) {
+	data := make([]byte, lookupTableMetaSize)
+	data = append(data, testKey(1).Bytes()...)
+	data = append(data, testKey(2).Bytes()...)
+	addrs, err := decodeLookupTableAddresses(data)
+	if err != nil || len(addrs) != 2 || !addrs[1].Equals(testKey(2)) {
+		t.Errorf("decode = %v, %v", addrs, err)
+	}
+	if _, err := decodeLookupTableAddresses(data[:70]); err == nil {
+		t.Error("truncated table accepted")
+	}
+}
+
+func TestRPCALTResolver_RefetchesExtendedTable(t *testing.T) {
+	table := func(n byte) map[string]interface{} {
+		data := make([]byte, lookupTableMetaSize)
+		for i := byte(0); i < n; i++ {
+			data = append(data, testKey(10+i).Bytes()...)
+		}
+		return map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": []interface{}{rpcAccount(1, ADDRESS_LOOKUP_TABLE_PROGRAM_ID, data)}}
+	}
+	node := &fakeRPC{results: map[string][]interface{}{"getMultipleAccounts": {table(2), table(4), table(4)}}, params: map[string][]interface{}{}}
+	r := NewRPCALTResolver(node)
+	lookup := solana.MessageAddressTableLookupSlice{{AccountKey: testKey(100), ReadonlyIndexes: []uint8{1}}}
+	if _, _, err := resolveLookups(context.Background(), lookup, r); err != nil {
+		t.Fatalf("resolveLookups: %v", err)
+	}
+
+	// the table was extended after being cached
+	lookup[0].ReadonlyIndexes = []uint8{3}
+	_, readonly, err := resolveLookups(context.Background(), lookup, r)
+	if err != nil || len(readonly) != 1 || !readonly[0].Equals(testKey(13)) {
+		t.Fatalf("resolveLookups = %v, %v", readonly, err)
+	}
+
+	// still out of range after one refetch
+	lookup[0].ReadonlyIndexes = []uint8{4}
+	if _, _, err := resolveLookups(context.Background(), lookup, r); err == nil {
+		t.Error("out-of-range index accepted")
+	}
+	if n := len(node.results["getMultipleAccounts"]); n != 0 {
+		t.Errorf("%d fetches left", n)
+	}
+}
@@ -0,0 +1,175 @@
+package solanaswapgo
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ConfidenceBalanceDelta is the Confidence of swaps rebuilt from balance deltas
+// (no venue decoded, so amounts may include unrelated flows).
+const ConfidenceBalanceDelta = 0.5
+
+// BalanceDeltaSwap is a swap rebuilt from the trader's balance changes when no
+// registered decoder matched (see Parser.BalanceDeltaFallback).
+type BalanceDeltaSwap struct {
+	Owner solana.PublicKey
+
+	InputMint     solana.PublicKey
+	InputAmount   uint64
+	InputDecimals uint8
+
+	OutputMint     solana.PublicKey
+	OutputAmount   uint64
+	OutputDecimals uint8
+}
+
+type ownedTokenBalance struct {
+	amount uint64
+	mint   solana.PublicKey
+}
+
+// balanceDeltaSwap nets the trader's pre/post balances per mint. Native SOL and
+// WSOL are one asset; the fee, Jito tips and the rent of token accounts opened
+// or closed in the transaction are added back so only the traded SOL remains.
+func (p *Parser) balanceDeltaSwap() *BalanceDeltaSwap {
+	trader := p.resolveTrader()
+	solMint := NATIVE_SOL_MINT_PROGRAM_ID
+
+	deltas := make(map[solana.PublicKey]int64)
+	decimals := map[solana.PublicKey]uint8{solMint: 9}
+
+	pre := make(map[uint16]ownedTokenBalance)
+	for _, b := range p.txMeta.PreTokenBalances {
+		if b.Owner == nil || !b.Owner.Equals(trader) || b.UiTokenAmount == nil {
+			continue
+		}
+		amt, _ := strconv.ParseUint(b.UiTokenAmount.Amount, 10, 64)
+		pre[b.AccountIndex] = ownedTokenBalance{amt, b.Mint}
+		decimals[b.Mint] = b.UiTokenAmount.Decimals
+	}
+	post := make(map[uint16]ownedTokenBalance)
+	for _, b := range p.txMeta.PostTokenBalances {
+		if b.Owner == nil || !b.Owner.Equals(trader) || b.UiTokenAmount == nil {
+			continue
+		}
+		amt, _ := strconv.ParseUint(b.UiTokenAmount.Amount, 10, 64)
+		post[b.AccountIndex] = ownedTokenBalance{amt, b.Mint}
+		decimals[b.Mint] = b.UiTokenAmount.Decimals
+	}
+
+	// Rent of accounts opened in the tx is charged to the fee payer; rent of
+	// closed accounts goes back to the owner.
+	paysFees := trader.Equals(p.FeePayer())
+	var rent int64
+	for idx, b := range post {
+		deltas[b.mint] += int64(b.amount) - int64(pre[idx].amount)
+		if _, existed := pre[idx]; !existed && paysFees {
+			rent += p.accountRent(idx, b)
+		}
+	}
+	for idx, b := range pre {
+		if _, ok := post[idx]; !ok { // closed
+			deltas[b.mint] -= int64(b.amount)
+			rent -= p.accountRent(idx, b)
+		}
+	}
+
+	if lamports, ok := p.lamportDeltaFor(trader); ok {
+		if paysFees {
+			lamports += int64(p.txMeta.Fee) + int64(p.jitoTips())
+		}
+		deltas[solMint] += lamports + rent
+	}
+
+	var ins, outs []solana.PublicKey
+	for mint, d := range deltas {
+		switch {
+		case d < 0:
+			ins = append(ins, mint)
+		case d > 0:
+			outs = append(outs, mint)
+		}
+	}
+	pick := func(mints []solana.PublicKey) (solana.PublicKey, bool) {
+		// SOL next to a token on the same side is fees/dust, not the trade.
+		if len(mints) > 1 {
+			filtered := mints[:0]
+			for _, m := range mints {
+				if !m.Equals(solMint) {
+					filtered = append(filtered, m)
+				}
+			}
+			mints = filtered
+		}
+		var best solana.PublicKey
+		bestSize := -1.0
+		for _, m := range mints {
+			size := math.Abs(float64(deltas[m])) / math.Pow10(int(decimals[m]))
+			if size > bestSize {
+				best, bestSize = m, size
+			}
+		}
+		return best, bestSize >= 0
+	}
+	in, okIn := pick(ins)
+	out, okOut := pick(outs)
+	if !okIn || !okOut || in.Equals(out) {
+		return nil
+	}
+	return &BalanceDeltaSwap{
+		Owner:          trader,
+		InputMint:      in,
+		InputAmount:    uint64(-deltas[in]),
+		InputDecimals:  decimals[in],
+		OutputMint:     out,
+		OutputAmount:   uint64(deltas[out]),
+		OutputDecimals: decimals[out],
+	}
+}
+
+// accountRent is the rent-exempt reserve held by a token account: its lamports
+// minus, for WSOL accounts, the wrapped amount.
+func (p *Parser) accountRent(idx uint16, b ownedTokenBalance) int64 {
+	var lamports uint64
+	switch {
+	case int(idx) < len(p.txMeta.PostBalances) && p.txMeta.PostBalances[idx] > 0:
+		lamports = p.txMeta.PostBalances[idx]
+	case int(idx) < len(p.txMeta.PreBalances):
+		lamports = p.txMeta.PreBalances[idx]
+	}
+	if b.mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+		lamports -= min(lamports, b.amount)
+	}
+	return int64(lamports)
+}
+
+// fillFromBalanceDelta resolves a SwapInfo from a balance-delta leg.
+func (p *Parser) fillFromBalanceDelta(swapInfo *SwapInfo, v *BalanceDeltaSwap) {
+	swapInfo.TokenInMint = v.InputMint
+	swapInfo.TokenInAmount = v.InputAmount
+	swapInfo.TokenInDecimals = v.InputDecimals
+	swapInfo.TokenOutMint = v.OutputMint
+	swapInfo.TokenOutAmount = v.OutputAmount
+	swapInfo.TokenOutDecimals = v.OutputDecimals
+	swapInfo.AMMs = []string{string(UNKNOWN)}
+	p.setMethod(swapInfo, MethodBalanceDelta)
+}
+
+// firstUnknownInstruction is the first outer instruction calling a program
+// that isn't a well-known system program; 0 if there is none.
+func (p *Parser) firstUnknownInstruction() int {
+	for i, inst := range p.txInfo.Message.Instructions {
+		progID := p.allAccountKeys[inst.ProgramIDIndex]
+		switch {
+		case progID.Equals(solana.SystemProgramID),
+			progID.Equals(COMPUTE_BUDGET_PROGRAM_ID),
+			progID.Equals(solana.SPLAssociatedTokenAccountProgramID),
+			p.isTokenProgram(progID):
+			continue
+		}
+		return i
+	}
+	return 0
+}
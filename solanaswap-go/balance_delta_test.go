@@ -0,0 +1,54 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestBalanceDeltaFallback_UnknownProgram(t *testing.T) {
+	bonkATA, poolBonk := testKey(1), testKey(2)
+	newParser := func() *Parser {
+		return newTestParser(t,
+			[]solana.PublicKey{testPayer, bonkATA, poolBonk, testVenueProgramID},
+			[]solana.CompiledInstruction{{ProgramIDIndex: 3, Accounts: []uint16{0, 1, 2}}},
+			&rpc.TransactionMeta{
+				Fee:          5_000,
+				PreBalances:  []uint64{1_000_000_000, 0, 2_039_280, 1},
+				PostBalances: []uint64{1_000_000_000 - 100_000_000 - 2_039_280 - 5_000, 2_039_280, 2_039_280, 1},
+				PreTokenBalances: []rpc.TokenBalance{
+					tokenBalance(2, testBonkMint, testVenueProgramID, "1000", 5),
+				},
+				PostTokenBalances: []rpc.TokenBalance{
+					tokenBalance(1, testBonkMint, testPayer, "500", 5),
+					tokenBalance(2, testBonkMint, testVenueProgramID, "500", 5),
+				},
+			},
+		)
+	}
+
+	if swaps, _ := newParser().ParseTransaction(); len(swaps) != 0 {
+		t.Fatalf("fallback ran without opt-in: %+v", swaps)
+	}
+
+	p := newParser()
+	p.BalanceDeltaFallback = true
+	swaps, err := p.ParseTransaction()
+	if err != nil || len(swaps) != 1 || swaps[0].Type != UNKNOWN {
+		t.Fatalf("ParseTransaction = %+v, %v", swaps, err)
+	}
+	info, err := p.ProcessSwapData(swaps)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if !info.TokenInMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || info.TokenInAmount != 100_000_000 {
+		t.Errorf("in = %s %d, want SOL 100000000 (rent and fee netted out)", info.TokenInMint, info.TokenInAmount)
+	}
+	if info.TokenOutMint.String() != testBonkMint || info.TokenOutAmount != 500 || info.TokenOutDecimals != 5 {
+		t.Errorf("out = %s %d/%d", info.TokenOutMint, info.TokenOutAmount, info.TokenOutDecimals)
+	}
+	if info.Confidence != ConfidenceBalanceDelta || len(info.AMMs) != 1 || info.AMMs[0] != string(UNKNOWN) {
+		t.Errorf("confidence/AMMs = %v/%v", info.Confidence, info.AMMs)
+	}
+}
@@ -0,0 +1,176 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// BlockSwap is a swap found in a block.
+type BlockSwap struct {
+	Signature solana.Signature
+	// TransactionIndex is the transaction's position in the block.
+	TransactionIndex int
+	Swap             *SwapInfo
+}
+
+// BlockError is a transaction of a block that couldn't be parsed.
+type BlockError struct {
+	Signature        solana.Signature
+	TransactionIndex int
+	Err              error
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("transaction %d (%s): %s", e.TransactionIndex, e.Signature, e.Err)
+}
+
+func (e *BlockError) Unwrap() error { return e.Err }
+
+// BlockSwaps is every swap of a block, in block order. Errors holds the
+// transactions that failed to decode; the rest of the block is still parsed.
+type BlockSwaps struct {
+	Slot   uint64
+	Swaps  []BlockSwap
+	Errors []BlockError
+}
+
+// BlockOptions configures ParseBlockWithOptions.
+type BlockOptions struct {
+	// Slot is set on every SwapInfo; getBlock doesn't return it.
+	Slot uint64
+	// Workers bounds how many transactions are decoded at once
+	// (default GOMAXPROCS).
+	Workers int
+	// Configure, if set, is applied to each transaction's parser before it
+	// parses, e.g. to register decoders or enable BalanceDeltaFallback.
+	Configure func(*Parser)
+}
+
+// ParseBlock extracts every swap of a block fetched with full transaction
+// details (and MaxSupportedTransactionVersion set, for v0 transactions).
+// Failed transactions and transactions without meta are skipped. Use
+// ParseBlockWithOptions to set the slot, which getBlock doesn't return.
+func ParseBlock(block *rpc.GetBlockResult) *BlockSwaps {
+	return ParseBlockWithOptions(block, BlockOptions{})
+}
+
+// ParseBlockWithOptions is ParseBlock with a slot, worker count and parser hook.
+func ParseBlockWithOptions(block *rpc.GetBlockResult, opts BlockOptions) *BlockSwaps {
+	res := &BlockSwaps{Slot: opts.Slot}
+	if block == nil {
+		return res
+	}
+	txs := block.Transactions
+	parseBlockTransactions(res, len(txs), block.BlockTime, opts, func(i int) (*solana.Transaction, *rpc.TransactionMeta, solana.Signature, error) {
+		var sig solana.Signature
+		if i < len(block.Signatures) {
+			sig = block.Signatures[i]
+		}
+		tx, err := txs[i].GetTransaction()
+		if err != nil {
+			return nil, nil, sig, fmt.Errorf("failed to decode transaction: %w", err)
+		}
+		return tx, txs[i].Meta, sig, nil
+	})
+	return res
+}
+
+// blockTransaction returns the i-th transaction of a block, its meta and, as a
+// fallback when the transaction has none, its signature from the block.
+type blockTransaction func(i int) (*solana.Transaction, *rpc.TransactionMeta, solana.Signature, error)
+
+type blockTxResult struct {
+	sig   solana.Signature
+	swaps []SwapInfo
+	err   error
+}
+
+// parseBlockTransactions decodes n transactions over a bounded worker pool and
+// appends their swaps and errors to res in block order.
+func parseBlockTransactions(res *BlockSwaps, n int, blockTime *solana.UnixTimeSeconds, opts BlockOptions, get blockTransaction) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	results := make([]blockTxResult, n)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = parseBlockTransaction(i, blockTime, opts, get)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, r := range results {
+		if r.err != nil {
+			res.Errors = append(res.Errors, BlockError{Signature: r.sig, TransactionIndex: i, Err: r.err})
+			continue
+		}
+		for j := range r.swaps {
+			res.Swaps = append(res.Swaps, BlockSwap{Signature: r.sig, TransactionIndex: i, Swap: &r.swaps[j]})
+		}
+	}
+}
+
+// parseBlockTransaction parses one transaction; a decoder panic on malformed
+// data is reported as its error rather than taking the block down.
+func parseBlockTransaction(i int, blockTime *solana.UnixTimeSeconds, opts BlockOptions, get blockTransaction) (r blockTxResult) {
+	defer func() {
+		if v := recover(); v != nil {
+			r.swaps, r.err = nil, fmt.Errorf("panic while parsing: %v", v)
+		}
+	}()
+
+	tx, meta, sig, err := get(i)
+	r.sig = sig
+	if err != nil {
+		r.err = err
+		return r
+	}
+	if tx == nil || meta == nil || meta.Err != nil {
+		return r
+	}
+	if len(tx.Signatures) > 0 {
+		r.sig = tx.Signatures[0]
+	}
+
+	p, err := NewTransactionParserFromTransaction(tx, meta)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	p.SetBlockInfo(opts.Slot, blockTime)
+	if opts.Configure != nil {
+		opts.Configure(p)
+	}
+	swapData, err := p.ParseTransaction()
+	if err != nil {
+		r.err = fmt.Errorf("failed to parse transaction: %w", err)
+		return r
+	}
+	if len(swapData) == 0 {
+		return r
+	}
+	r.swaps, err = p.ProcessAllSwaps(swapData)
+	if err != nil {
+		r.err = fmt.Errorf("failed to process swaps: %w", err)
+	}
+	return r
+}
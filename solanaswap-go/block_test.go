@@ -0,0 +1,92 @@
+package solanaswapgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestParseBlockTransactions(t *testing.T) {
+	swapTx := func(sig byte, failed bool) (*solana.Transaction, *rpc.TransactionMeta) {
+		p, _ := newRaydiumV4Parser(t)
+		tx, meta := *p.txInfo, *p.txMeta
+		tx.Signatures = []solana.Signature{{sig}}
+		if failed {
+			meta.Err = map[string]interface{}{"InstructionError": []interface{}{0, map[string]interface{}{"Custom": 30}}}
+		}
+		return &tx, &meta
+	}
+	errDecode := errors.New("bad encoding")
+
+	// 0: swap, 1: failed swap, 2: no swap, 3: undecodable, 4: panics, 5: no meta, 6: swap
+	get := func(i int) (*solana.Transaction, *rpc.TransactionMeta, solana.Signature, error) {
+		sig := solana.Signature{byte(i)}
+		switch i {
+		case 0, 6:
+			tx, meta := swapTx(byte(i), false)
+			return tx, meta, sig, nil
+		case 1:
+			tx, meta := swapTx(1, true)
+			return tx, meta, sig, nil
+		case 2:
+			return newTestParser(t, []solana.PublicKey{testPayer}, nil, nil).txInfo, &rpc.TransactionMeta{}, sig, nil
+		case 3:
+			return nil, nil, sig, errDecode
+		case 4:
+			panic("index out of range")
+		default:
+			tx, _ := swapTx(5, false)
+			return tx, nil, sig, nil
+		}
+	}
+
+	blockTime := solana.UnixTimeSeconds(1_700_000_000)
+	res := &BlockSwaps{Slot: 250}
+	parseBlockTransactions(res, 7, &blockTime, BlockOptions{Slot: 250, Workers: 3}, get)
+
+	if len(res.Swaps) != 2 {
+		t.Fatalf("got %d swaps, want 2: %+v", len(res.Swaps), res.Swaps)
+	}
+	for k, want := range []int{0, 6} {
+		s := res.Swaps[k]
+		if s.TransactionIndex != want || s.Signature != (solana.Signature{byte(want)}) {
+			t.Errorf("swap %d: index %d, signature %s", k, s.TransactionIndex, s.Signature)
+		}
+		if s.Swap.Slot != 250 || s.Swap.Timestamp.Unix() != 1_700_000_000 || s.Swap.TokenOutAmount != 42_000 {
+			t.Errorf("swap %d: slot %d, time %s, out %d", k, s.Swap.Slot, s.Swap.Timestamp, s.Swap.TokenOutAmount)
+		}
+	}
+
+	if len(res.Errors) != 2 {
+		t.Fatalf("got %d errors, want 2: %+v", len(res.Errors), res.Errors)
+	}
+	if e := res.Errors[0]; e.TransactionIndex != 3 || !errors.Is(&e, errDecode) {
+		t.Errorf("error 0 = %v", &e)
+	}
+	if e := res.Errors[1]; e.TransactionIndex != 4 || e.Signature != (solana.Signature{}) {
+		t.Errorf("error 1 = %v", &e)
+	}
+}
+
+func TestParseBlockTransactions_Configure(t *testing.T) {
+	get := func(int) (*solana.Transaction, *rpc.TransactionMeta, solana.Signature, error) {
+		p, _ := newRaydiumV4Parser(t)
+		return p.txInfo, p.txMeta, solana.Signature{}, nil
+	}
+	res := &BlockSwaps{}
+	parseBlockTransactions(res, 4, nil, BlockOptions{Configure: func(p *Parser) {
+		p.RegisterDecoder(NewProtocolDecoder("disabled", RoleAMM, []solana.PublicKey{RAYDIUM_V4_PROGRAM_ID},
+			func(*Parser, int) []SwapData { return nil }))
+	}}, get)
+	if len(res.Swaps) != 0 || len(res.Errors) != 0 {
+		t.Errorf("swaps = %+v, errors = %+v", res.Swaps, res.Errors)
+	}
+}
+
+func TestParseBlock_Nil(t *testing.T) {
+	if res := ParseBlockWithOptions(nil, BlockOptions{Slot: 9}); res.Slot != 9 || len(res.Swaps)+len(res.Errors) != 0 {
+		t.Errorf("res = %+v", res)
+	}
+}
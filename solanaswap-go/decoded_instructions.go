@@ -0,0 +1,87 @@
+package solanaswapgo
+
+import (
+	"github.com/P-HOW/solana-swap-decode/solanaswap-go/idl"
+	"github.com/gagliardetto/solana-go"
+)
+
+// DecodedInstruction is an instruction (or emit_cpi event) of a program with
+// a registered IDL, decoded generically.
+type DecodedInstruction struct {
+	ProgramID        solana.PublicKey
+	InstructionIndex int // outer instruction
+	InnerIndex       int // position in the inner set; -1 for the outer instruction
+	Name             string
+	Event            bool
+	Args             map[string]interface{}
+	// Accounts maps the IDL account names to their keys; events have none.
+	Accounts map[string]solana.PublicKey
+}
+
+// RegisterIDL makes DecodedInstructions decode prog's instructions and events.
+// A later IDL for the same program ID replaces the earlier one.
+func (p *Parser) RegisterIDL(prog *idl.Program) {
+	if p.idlPrograms == nil {
+		p.idlPrograms = make(map[solana.PublicKey]*idl.Program)
+	}
+	p.idlPrograms[prog.ID] = prog
+}
+
+// DecodedInstructions decodes every outer and inner instruction whose program
+// has a registered IDL, in execution order. Instructions whose data matches
+// no IDL entry are skipped.
+func (p *Parser) DecodedInstructions() []DecodedInstruction {
+	if len(p.idlPrograms) == 0 {
+		return nil
+	}
+	var out []DecodedInstruction
+	for i, outer := range p.txInfo.Message.Instructions {
+		for j, inst := range append([]solana.CompiledInstruction{outer}, p.getInnerInstructions(i)...) {
+			if d, ok := p.decodeWithIDL(inst); ok {
+				d.InstructionIndex = i
+				d.InnerIndex = j - 1
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
+func (p *Parser) decodeWithIDL(inst solana.CompiledInstruction) (DecodedInstruction, bool) {
+	if int(inst.ProgramIDIndex) >= len(p.allAccountKeys) {
+		return DecodedInstruction{}, false
+	}
+	prog, ok := p.idlPrograms[p.allAccountKeys[inst.ProgramIDIndex]]
+	if !ok {
+		return DecodedInstruction{}, false
+	}
+	d := DecodedInstruction{ProgramID: prog.ID}
+
+	if isAnchorEventCPI(inst.Data) {
+		ev, fields, err := prog.DecodeEvent(inst.Data)
+		if err != nil {
+			if ev != nil {
+				p.Log.Errorf("error decoding %s event: %s", prog.IDL.Name, err)
+			}
+			return DecodedInstruction{}, false
+		}
+		d.Name, d.Event, d.Args = ev.Name, true, fields
+		return d, true
+	}
+
+	ix, args, err := prog.DecodeInstruction(inst.Data)
+	if err != nil {
+		if ix != nil {
+			p.Log.Errorf("error decoding %s instruction: %s", prog.IDL.Name, err)
+		}
+		return DecodedInstruction{}, false
+	}
+	d.Name, d.Args = ix.Name, args
+	d.Accounts = make(map[string]solana.PublicKey, len(ix.Accounts))
+	for k, acc := range ix.Accounts {
+		if k < len(inst.Accounts) && int(inst.Accounts[k]) < len(p.allAccountKeys) {
+			d.Accounts[acc.Name] = p.allAccountKeys[inst.Accounts[k]]
+		}
+	}
+	return d, true
+}
@@ -0,0 +1,64 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/P-HOW/solana-swap-decode/solanaswap-go/idl"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const testVenueIDL = `{
+  "metadata": {"name": "venue"},
+  "instructions": [{
+    "name": "swap",
+    "discriminator": [1, 1, 1, 1, 1, 1, 1, 1],
+    "accounts": [{"name": "pool", "writable": true}, {"name": "user", "signer": true}],
+    "args": [{"name": "amount_in", "type": "u64"}]
+  }],
+  "events": [{"name": "Swapped", "discriminator": [2, 2, 2, 2, 2, 2, 2, 2]}],
+  "types": [{"name": "Swapped", "type": {"kind": "struct", "fields": [{"name": "amount_out", "type": "u64"}]}}]
+}`
+
+func TestDecodedInstructions(t *testing.T) {
+	parsed, err := idl.Parse([]byte(testVenueIDL))
+	if err != nil {
+		t.Fatalf("idl.Parse: %v", err)
+	}
+	prog, err := idl.NewProgram(parsed, testVenueProgramID)
+	if err != nil {
+		t.Fatalf("idl.NewProgram: %v", err)
+	}
+
+	pool := testKey(1)
+	swap := binary.LittleEndian.AppendUint64([]byte{1, 1, 1, 1, 1, 1, 1, 1}, 500)
+	event := append(append([]byte{}, anchorEventCPIPrefix...), 2, 2, 2, 2, 2, 2, 2, 2)
+	event = binary.LittleEndian.AppendUint64(event, 42)
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index:        0,
+			Instructions: []rpc.CompiledInstruction{{ProgramIDIndex: 2, Accounts: []uint16{2}, Data: event}},
+		}},
+	}
+	p := newTestParser(t, []solana.PublicKey{testPayer, pool, testVenueProgramID},
+		[]solana.CompiledInstruction{{ProgramIDIndex: 2, Accounts: []uint16{1, 0}, Data: swap}}, meta)
+
+	if got := p.DecodedInstructions(); got != nil {
+		t.Fatalf("without IDL: %+v", got)
+	}
+	p.RegisterIDL(prog)
+	got := p.DecodedInstructions()
+	if len(got) != 2 {
+		t.Fatalf("got %d decoded, want 2: %+v", len(got), got)
+	}
+	if got[0].Name != "swap" || got[0].Event || got[0].InnerIndex != -1 || got[0].Args["amount_in"] != uint64(500) {
+		t.Errorf("instruction = %+v", got[0])
+	}
+	if !got[0].Accounts["pool"].Equals(pool) || !got[0].Accounts["user"].Equals(testPayer) {
+		t.Errorf("accounts = %v", got[0].Accounts)
+	}
+	if got[1].Name != "Swapped" || !got[1].Event || got[1].InnerIndex != 0 || got[1].Args["amount_out"] != uint64(42) {
+		t.Errorf("event = %+v", got[1])
+	}
+}
@@ -0,0 +1,201 @@
+package solanaswapgo
+
+import "github.com/gagliardetto/solana-go"
+
+// DecoderRole tells ParseTransaction in which pass a ProtocolDecoder runs.
+type DecoderRole int
+
+const (
+	// RoleRouter decoders run in the first pass over outer instructions. As soon as
+	// one of them yields swaps, the direct-AMM pass is skipped (Jupiter, OKX, Moonshot).
+	RoleRouter DecoderRole = iota
+	// RoleWrapper decoders also run in the first pass but never suppress the
+	// direct-AMM pass (sniper bots wrapping an AMM CPI).
+	RoleWrapper
+	// RoleAMM decoders run in the second pass over outer instructions and are
+	// dispatched by routers/wrappers for matching inner instructions.
+	RoleAMM
+)
+
+// DecodeFunc decodes the swap legs produced under the outer instruction at instructionIndex.
+type DecodeFunc func(p *Parser, instructionIndex int) []SwapData
+
+// ProtocolDecoder turns the instructions of one venue into SwapData legs.
+//
+// Decoders sharing a Name are treated as one protocol: routers decode each
+// protocol at most once per outer instruction.
+type ProtocolDecoder interface {
+	Name() string
+	ProgramIDs() []solana.PublicKey
+	Role() DecoderRole
+	Decode(p *Parser, instructionIndex int) []SwapData
+}
+
+// NodeDecodeFunc decodes the swap legs of a single AMM invocation in the CPI tree.
+type NodeDecodeFunc func(p *Parser, node *InstructionNode) []SwapData
+
+// NodeDecoder is implemented by decoders that can decode one invocation inside
+// a router's CPI tree. Routers then decode each invocation separately instead
+// of the protocol once per outer instruction.
+type NodeDecoder interface {
+	DecodeNode(p *Parser, node *InstructionNode) []SwapData
+}
+
+type protocolDecoder struct {
+	name       string
+	role       DecoderRole
+	programIDs []solana.PublicKey
+	decode     DecodeFunc
+}
+
+// NewProtocolDecoder builds a ProtocolDecoder from a plain decode function.
+func NewProtocolDecoder(name string, role DecoderRole, programIDs []solana.PublicKey, decode DecodeFunc) ProtocolDecoder {
+	return &protocolDecoder{name: name, role: role, programIDs: programIDs, decode: decode}
+}
+
+func (d *protocolDecoder) Name() string                   { return d.name }
+func (d *protocolDecoder) ProgramIDs() []solana.PublicKey { return d.programIDs }
+func (d *protocolDecoder) Role() DecoderRole              { return d.role }
+func (d *protocolDecoder) Decode(p *Parser, instructionIndex int) []SwapData {
+	return d.decode(p, instructionIndex)
+}
+
+type protocolNodeDecoder struct {
+	protocolDecoder
+	decodeNode NodeDecodeFunc
+}
+
+// NewProtocolNodeDecoder builds a ProtocolDecoder that also implements
+// NodeDecoder.
+func NewProtocolNodeDecoder(name string, role DecoderRole, programIDs []solana.PublicKey, decode DecodeFunc, decodeNode NodeDecodeFunc) ProtocolDecoder {
+	return &protocolNodeDecoder{
+		protocolDecoder: protocolDecoder{name: name, role: role, programIDs: programIDs, decode: decode},
+		decodeNode:      decodeNode,
+	}
+}
+
+func (d *protocolNodeDecoder) DecodeNode(p *Parser, node *InstructionNode) []SwapData {
+	return d.decodeNode(p, node)
+}
+
+// transfersUnderNode is the NodeDecodeFunc of venues whose legs are just the
+// token transfers they make.
+func transfersUnderNode(swapType SwapType) NodeDecodeFunc {
+	return func(p *Parser, node *InstructionNode) []SwapData {
+		return p.TokenTransfersUnderNode(node, swapType)
+	}
+}
+
+// defaultDecoders lists the built-in venues every Parser starts with.
+func defaultDecoders() []ProtocolDecoder {
+	return []ProtocolDecoder{
+		NewProtocolDecoder(PROTOCOL_JUPITER, RoleRouter,
+			[]solana.PublicKey{JUPITER_PROGRAM_ID},
+			(*Parser).processJupiterSwaps),
+		// Moonshot decodes its own outer instruction arguments, so it claims the
+		// transaction the same way a router does.
+		NewProtocolDecoder(PROTOCOL_MOONSHOT, RoleRouter,
+			[]solana.PublicKey{MOONSHOT_PROGRAM_ID},
+			(*Parser).processMoonshotSwaps),
+		NewProtocolDecoder(PROTOCOL_OKX, RoleRouter,
+			[]solana.PublicKey{OKX_DEX_ROUTER_PROGRAM_ID},
+			(*Parser).processOKXSwaps), // includes aggregate + legs
+		NewProtocolDecoder(PROTOCOL_BOTS, RoleWrapper,
+			[]solana.PublicKey{
+				BANANA_GUN_PROGRAM_ID,
+				MINTECH_PROGRAM_ID,
+				BLOOM_PROGRAM_ID,
+				NOVA_PROGRAM_ID,
+				MAESTRO_PROGRAM_ID,
+			},
+			(*Parser).processRouterSwaps),
+		NewProtocolNodeDecoder(PROTOCOL_RAYDIUM, RoleAMM,
+			[]solana.PublicKey{
+				RAYDIUM_V4_PROGRAM_ID,
+				RAYDIUM_CPMM_PROGRAM_ID,
+				RAYDIUM_AMM_PROGRAM_ID,
+				RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID,
+				RAYDIUM_LAUNCHLAB_PROGRAM_ID,
+				solana.MustPublicKeyFromBase58("AP51WLiiqTdbZfgyRMs35PsZpdmLuPDdHYmrB23pEtMU"),
+			},
+			(*Parser).processRaydSwaps, transfersUnderNode(RAYDIUM)),
+		NewProtocolNodeDecoder(PROTOCOL_ORCA, RoleAMM,
+			[]solana.PublicKey{ORCA_PROGRAM_ID},
+			(*Parser).processOrcaSwaps, transfersUnderNode(ORCA)),
+		NewProtocolNodeDecoder(PROTOCOL_METEORA, RoleAMM,
+			[]solana.PublicKey{
+				METEORA_PROGRAM_ID,
+				METEORA_POOLS_PROGRAM_ID,
+				METEORA_DLMM_PROGRAM_ID,
+				METEORA_DBC_PROGRAM_ID,
+				METEORA_DAMM_V2_PROGRAM_ID,
+			},
+			(*Parser).processMeteoraSwaps, transfersUnderNode(METEORA)),
+		// Pump.fun AMM and the bonding curve share a protocol name so routers
+		// only harvest one of them per instruction (previous behavior).
+		NewProtocolDecoder(PROTOCOL_PUMPFUN, RoleAMM,
+			[]solana.PublicKey{PUMPFUN_AMM_PROGRAM_ID},
+			(*Parser).processPumpfunAMMSwaps),
+		NewProtocolDecoder(PROTOCOL_PUMPFUN, RoleAMM,
+			[]solana.PublicKey{
+				PUMP_FUN_PROGRAM_ID,
+				solana.MustPublicKeyFromBase58("BSfD6SHZigAfDWSjzD5Q41jw8LmKwtmjskPH9XW1mrRW"),
+			},
+			(*Parser).processPumpfunSwaps),
+		NewProtocolDecoder(PROTOCOL_PHOENIX, RoleAMM,
+			[]solana.PublicKey{PHOENIX_PROGRAM_ID},
+			(*Parser).processPhoenixSwaps),
+	}
+}
+
+// RegisterDecoder adds d to the parser's registry. A decoder registered later
+// replaces any earlier decoder for the same program ID, so built-in venues can
+// be overridden as well as extended.
+func (p *Parser) RegisterDecoder(d ProtocolDecoder) {
+	if p.decoders == nil {
+		p.decoders = make(map[solana.PublicKey]ProtocolDecoder)
+	}
+	for _, id := range d.ProgramIDs() {
+		p.decoders[id] = d
+	}
+}
+
+// decoderFor returns the registered decoder for progID, or nil.
+func (p *Parser) decoderFor(progID solana.PublicKey) ProtocolDecoder {
+	return p.decoders[progID]
+}
+
+// ---- Helpers for decoders living outside this package ----
+
+// AccountKeys returns the static message keys followed by the loaded writable and readonly keys.
+func (p *Parser) AccountKeys() solana.PublicKeySlice {
+	return p.allAccountKeys
+}
+
+// OuterInstruction returns the top-level instruction at index.
+func (p *Parser) OuterInstruction(index int) solana.CompiledInstruction {
+	return p.txInfo.Message.Instructions[index]
+}
+
+// InnerInstructions returns the CPIs recorded under the outer instruction at index.
+func (p *Parser) InnerInstructions(index int) []solana.CompiledInstruction {
+	return p.getInnerInstructions(index)
+}
+
+// TokenTransfersUnder decodes every Transfer/TransferChecked recorded under the
+// outer instruction at index and tags the legs with swapType.
+func (p *Parser) TokenTransfersUnder(index int, swapType SwapType) []SwapData {
+	var legs []SwapData
+	for _, inst := range p.getInnerInstructions(index) {
+		if p.isTransferCheck(inst) {
+			if tr := p.processTransferCheck(inst); tr != nil {
+				legs = append(legs, SwapData{Type: swapType, Data: tr})
+			}
+		} else if p.isTransfer(inst) {
+			if tr := p.processTransfer(inst); tr != nil {
+				legs = append(legs, SwapData{Type: swapType, Data: tr})
+			}
+		}
+	}
+	return legs
+}
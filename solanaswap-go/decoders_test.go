@@ -0,0 +1,91 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var (
+	testPayer           = solana.MustPublicKeyFromBase58("4k8WHszi2uBzTiypTKUYH1hzYkUBCARPPn6ZjPNMhDoc")
+	testVenueProgramID  = solana.PublicKeyFromBytes(bytes.Repeat([]byte{7}, 32))
+	testRouterProgramID = solana.PublicKeyFromBytes(bytes.Repeat([]byte{8}, 32))
+)
+
+func newTestParser(t *testing.T, keys []solana.PublicKey, outer []solana.CompiledInstruction, meta *rpc.TransactionMeta) *Parser {
+	t.Helper()
+	tx := &solana.Transaction{
+		Signatures: []solana.Signature{{1}},
+		Message: solana.Message{
+			Header:       solana.MessageHeader{NumRequiredSignatures: 1},
+			AccountKeys:  keys,
+			Instructions: outer,
+		},
+	}
+	if meta == nil {
+		meta = &rpc.TransactionMeta{}
+	}
+	p, err := NewTransactionParserFromTransaction(tx, meta)
+	if err != nil {
+		t.Fatalf("NewTransactionParserFromTransaction: %v", err)
+	}
+	return p
+}
+
+func TestRegisterDecoder_DispatchesCustomAMM(t *testing.T) {
+	p := newTestParser(t,
+		[]solana.PublicKey{testPayer, testVenueProgramID},
+		[]solana.CompiledInstruction{{ProgramIDIndex: 1}},
+		nil,
+	)
+
+	var calledWith []int
+	p.RegisterDecoder(NewProtocolDecoder("venue", RoleAMM, []solana.PublicKey{testVenueProgramID},
+		func(p *Parser, instructionIndex int) []SwapData {
+			calledWith = append(calledWith, instructionIndex)
+			return []SwapData{{Type: "Venue", Data: &TransferData{Mint: "m"}}}
+		}))
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	if len(calledWith) != 1 || calledWith[0] != 0 {
+		t.Fatalf("decoder called with %v, want [0]", calledWith)
+	}
+	if len(swaps) != 1 || swaps[0].Type != "Venue" {
+		t.Fatalf("unexpected swaps: %+v", swaps)
+	}
+}
+
+func TestRegisterDecoder_RouterSkipsAMMPass(t *testing.T) {
+	p := newTestParser(t,
+		[]solana.PublicKey{testPayer, testRouterProgramID, testVenueProgramID},
+		[]solana.CompiledInstruction{{ProgramIDIndex: 1}, {ProgramIDIndex: 2}},
+		nil,
+	)
+
+	ammCalls := 0
+	p.RegisterDecoder(NewProtocolDecoder("router", RoleRouter, []solana.PublicKey{testRouterProgramID},
+		func(p *Parser, instructionIndex int) []SwapData {
+			return []SwapData{{Type: "Router", Data: &TransferData{Mint: "m"}}}
+		}))
+	p.RegisterDecoder(NewProtocolDecoder("venue", RoleAMM, []solana.PublicKey{testVenueProgramID},
+		func(p *Parser, instructionIndex int) []SwapData {
+			ammCalls++
+			return nil
+		}))
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	if ammCalls != 0 {
+		t.Fatalf("AMM pass ran %d times after a router produced swaps", ammCalls)
+	}
+	if len(swaps) != 1 || swaps[0].Type != "Router" {
+		t.Fatalf("unexpected swaps: %+v", swaps)
+	}
+}
@@ -90,16 +90,6 @@ func (p *Parser) processJupiterSwaps(instructionIndex int) []SwapData {
 	return swaps
 }
 
-// containsDCAProgram checks if the transaction contains the Jupiter DCA program.
-func (p *Parser) containsDCAProgram() bool {
-	for _, accountKey := range p.allAccountKeys {
-		if accountKey.Equals(JUPITER_DCA_PROGRAM_ID) {
-			return true
-		}
-	}
-	return false
-}
-
 func (p *Parser) parseJupiterRouteEventInstruction(instruction solana.CompiledInstruction) (*JupiterSwapEventData, error) {
 	decodedBytes, err := base58.Decode(instruction.Data.String())
 	if err != nil {
@@ -156,7 +146,7 @@ func (p *Parser) extractSPLDecimals() error {
 		if len(instr.Data) == 0 || (instr.Data[0] != 3 && instr.Data[0] != 12) {
 			return
 		}
-		if len(instr.Accounts) < 3 {
+		if len(instr.Accounts) < 3 || int(instr.Accounts[1]) >= len(p.allAccountKeys) {
 			return
 		}
 		mint := p.allAccountKeys[instr.Accounts[1]].String()
@@ -169,7 +169,7 @@ func (p *Parser) processOKXSwaps(instructionIndex int) []SwapData {
 func (p *Parser) processOKXRouterSwaps(instructionIndex int) []SwapData {
 	var swaps []SwapData
 	seen := make(map[string]bool)
-	processedProtocols := make(map[SwapType]bool)
+	processedProtocols := make(map[string]bool)
 
 	innerInstructions := p.getInnerInstructions(instructionIndex)
 	p.Log.Infof("processing okx router swaps for instruction %d: %d inner instructions", instructionIndex, len(innerInstructions))
@@ -179,74 +179,19 @@ func (p *Parser) processOKXRouterSwaps(instructionIndex int) []SwapData {
 	}
 
 	for _, inner := range innerInstructions {
-		progID := p.allAccountKeys[inner.ProgramIDIndex]
-
-		switch {
-		case progID.Equals(RAYDIUM_V4_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CPMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
-			if processedProtocols[RAYDIUM] {
-				continue
-			}
-			if raydSwaps := p.processRaydSwaps(instructionIndex); len(raydSwaps) > 0 {
-				for _, swap := range raydSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
-				}
-				processedProtocols[RAYDIUM] = true
-			}
-
-		case progID.Equals(ORCA_PROGRAM_ID):
-			if processedProtocols[ORCA] {
-				continue
-			}
-			if orcaSwaps := p.processOrcaSwaps(instructionIndex); len(orcaSwaps) > 0 {
-				for _, swap := range orcaSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
-				}
-				processedProtocols[ORCA] = true
-			}
-
-		case progID.Equals(METEORA_PROGRAM_ID) ||
-			progID.Equals(METEORA_POOLS_PROGRAM_ID) ||
-			progID.Equals(METEORA_DLMM_PROGRAM_ID) ||
-			progID.Equals(METEORA_DBC_PROGRAM_ID): // NEW: DBC recognized under OKX router
-			if processedProtocols[METEORA] {
-				continue
-			}
-			if meteoraSwaps := p.processMeteoraSwaps(instructionIndex); len(meteoraSwaps) > 0 {
-				for _, swap := range meteoraSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
-				}
-				processedProtocols[METEORA] = true
-			}
-
-		case progID.Equals(PUMP_FUN_PROGRAM_ID):
-			if processedProtocols[PUMP_FUN] {
-				continue
-			}
-			if pumpfunSwaps := p.processPumpfunSwaps(instructionIndex); len(pumpfunSwaps) > 0 {
-				for _, swap := range pumpfunSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
+		d := p.decoderFor(p.allAccountKeys[inner.ProgramIDIndex])
+		if d == nil || d.Role() != RoleAMM || processedProtocols[d.Name()] {
+			continue
+		}
+		if legs := d.Decode(p, instructionIndex); len(legs) > 0 {
+			for _, swap := range legs {
+				key := getSwapKey(swap)
+				if !seen[key] {
+					swaps = append(swaps, swap)
+					seen[key] = true
 				}
-				processedProtocols[PUMP_FUN] = true
 			}
+			processedProtocols[d.Name()] = true
 		}
 	}
 
@@ -0,0 +1,191 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Phoenix logs its market events through a self-CPI "Log" instruction (tag 15):
+// a Header event followed by a borsh Vec of events.
+const phoenixLogInstruction = 15
+
+// PhoenixMarketEvent tags.
+const (
+	phoenixEventHeader       = 1
+	phoenixEventFill         = 2
+	phoenixEventPlace        = 3
+	phoenixEventReduce       = 4
+	phoenixEventEvict        = 5
+	phoenixEventFillSummary  = 6
+	phoenixEventFee          = 7
+	phoenixEventTimeInForce  = 8
+	phoenixEventExpiredOrder = 9
+)
+
+// phoenixEventSizes are the payload sizes of the events we skip over.
+var phoenixEventSizes = map[uint8]uint{
+	phoenixEventPlace:        42,
+	phoenixEventReduce:       34,
+	phoenixEventEvict:        58,
+	phoenixEventFee:          10,
+	phoenixEventTimeInForce:  26,
+	phoenixEventExpiredOrder: 58,
+}
+
+// PhoenixFill is one maker order matched by the taker.
+type PhoenixFill struct {
+	Maker               solana.PublicKey
+	OrderSequenceNumber uint64
+	PriceInTicks        uint64
+	BaseLotsFilled      uint64
+	BaseLotsRemaining   uint64 // left on the maker order
+}
+
+// PhoenixTradeEvent is the taker side of a Phoenix swap or IOC order, decoded
+// from the market's event log. Amounts are in market lots; token amounts come
+// from the transfer legs decoded next to it.
+type PhoenixTradeEvent struct {
+	Market         solana.PublicKey
+	Taker          solana.PublicKey
+	SequenceNumber uint64
+	IsBuy          bool // taker bought base (matched against asks)
+
+	Fills           []PhoenixFill
+	BaseLotsFilled  uint64
+	QuoteLotsFilled uint64
+	FeeInQuoteLots  uint64
+}
+
+type phoenixAuditLogHeader struct {
+	Instruction    uint8
+	SequenceNumber uint64
+	Timestamp      int64
+	Slot           uint64
+	Market         solana.PublicKey
+	Signer         solana.PublicKey
+	TotalEvents    uint16
+}
+
+type phoenixFillEvent struct {
+	Index               uint16
+	MakerID             solana.PublicKey
+	OrderSequenceNumber uint64
+	PriceInTicks        uint64
+	BaseLotsFilled      uint64
+	BaseLotsRemaining   uint64
+}
+
+// processPhoenixSwaps emits one PhoenixTradeEvent per event batch that filled
+// orders, followed by the token transfers under the instruction. Phoenix
+// instructions that matched nothing (deposits, cancels, resting orders) yield
+// no legs.
+func (p *Parser) processPhoenixSwaps(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, inst := range p.getInnerInstructions(instructionIndex) {
+		if !p.isPhoenixLogInstruction(inst) {
+			continue
+		}
+		event, err := parsePhoenixLog(inst.Data)
+		if err != nil {
+			p.Log.Errorf("error processing Phoenix log: %s", err)
+			continue
+		}
+		if event != nil {
+			swaps = append(swaps, SwapData{Type: PHOENIX, Data: event})
+		}
+	}
+	if len(swaps) == 0 {
+		return nil
+	}
+	return append(swaps, p.TokenTransfersUnder(instructionIndex, PHOENIX)...)
+}
+
+func (p *Parser) isPhoenixLogInstruction(inst solana.CompiledInstruction) bool {
+	return p.allAccountKeys[inst.ProgramIDIndex].Equals(PHOENIX_PROGRAM_ID) &&
+		len(inst.Data) > 0 && inst.Data[0] == phoenixLogInstruction
+}
+
+// parsePhoenixLog decodes one Log instruction; nil when the batch has no fills.
+func parsePhoenixLog(data []byte) (*PhoenixTradeEvent, error) {
+	if len(data) < 2 || data[0] != phoenixLogInstruction {
+		return nil, fmt.Errorf("not a phoenix log instruction")
+	}
+	decoder := ag_binary.NewBorshDecoder(data[1:])
+
+	tag, err := decoder.ReadUint8()
+	if err != nil || tag != phoenixEventHeader {
+		return nil, fmt.Errorf("phoenix log does not start with a header event")
+	}
+	var header phoenixAuditLogHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("error unmarshaling phoenix header: %s", err)
+	}
+	count, err := decoder.ReadUint32(binary.LittleEndian)
+	if err != nil {
+		return nil, fmt.Errorf("error reading phoenix event count: %s", err)
+	}
+
+	event := &PhoenixTradeEvent{
+		Market:         header.Market,
+		Taker:          header.Signer,
+		SequenceNumber: header.SequenceNumber,
+	}
+	var summary bool
+	for i := uint32(0); i < count; i++ {
+		tag, err := decoder.ReadUint8()
+		if err != nil {
+			return nil, fmt.Errorf("error reading phoenix event %d: %s", i, err)
+		}
+		switch tag {
+		case phoenixEventFill:
+			var fill phoenixFillEvent
+			if err := decoder.Decode(&fill); err != nil {
+				return nil, fmt.Errorf("error unmarshaling phoenix fill: %s", err)
+			}
+			event.Fills = append(event.Fills, PhoenixFill{
+				Maker:               fill.MakerID,
+				OrderSequenceNumber: fill.OrderSequenceNumber,
+				PriceInTicks:        fill.PriceInTicks,
+				BaseLotsFilled:      fill.BaseLotsFilled,
+				BaseLotsRemaining:   fill.BaseLotsRemaining,
+			})
+		case phoenixEventFillSummary:
+			// index u16, client_order_id u128, then the totals
+			if err := decoder.SkipBytes(2 + 16); err != nil {
+				return nil, fmt.Errorf("error reading phoenix fill summary: %s", err)
+			}
+			var totals [3]uint64
+			for j := range totals {
+				if totals[j], err = decoder.ReadUint64(binary.LittleEndian); err != nil {
+					return nil, fmt.Errorf("error reading phoenix fill summary: %s", err)
+				}
+			}
+			event.BaseLotsFilled, event.QuoteLotsFilled, event.FeeInQuoteLots = totals[0], totals[1], totals[2]
+			summary = true
+		default:
+			size, ok := phoenixEventSizes[tag]
+			if !ok {
+				return nil, fmt.Errorf("unknown phoenix event tag %d", tag)
+			}
+			if err := decoder.SkipBytes(size); err != nil {
+				return nil, fmt.Errorf("error skipping phoenix event %d: %s", tag, err)
+			}
+		}
+	}
+
+	if len(event.Fills) == 0 {
+		return nil, nil
+	}
+	// Bid sequence numbers are stored bit-inverted, so their top bit is set.
+	// Matching bids means the taker sold base.
+	event.IsBuy = event.Fills[0].OrderSequenceNumber>>63 == 0
+	if !summary {
+		for _, f := range event.Fills {
+			event.BaseLotsFilled += f.BaseLotsFilled
+		}
+	}
+	return event, nil
+}
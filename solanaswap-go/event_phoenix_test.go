@@ -0,0 +1,121 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// phoenixLogData encodes a Log instruction: header, then a fill against
+// makerSeq and its fill summary.
+func phoenixLogData(market, signer, maker solana.PublicKey, makerSeq, price, baseLots, quoteLots uint64) []byte {
+	u16 := func(b []byte, v uint16) []byte { return binary.LittleEndian.AppendUint16(b, v) }
+	u64 := func(b []byte, v uint64) []byte { return binary.LittleEndian.AppendUint64(b, v) }
+
+	data := []byte{phoenixLogInstruction, phoenixEventHeader, 0} // header for a Swap (0)
+	data = u64(data, 77)                                         // sequence number
+	data = u64(data, 1_700_000_000)                              // timestamp
+	data = u64(data, 250_000_000)                                // slot
+	data = append(data, market.Bytes()...)
+	data = append(data, signer.Bytes()...)
+	data = u16(data, 3)
+	data = binary.LittleEndian.AppendUint32(data, 3)
+
+	data = append(data, phoenixEventFill)
+	data = u16(data, 1)
+	data = append(data, maker.Bytes()...)
+	data = u64(data, makerSeq)
+	data = u64(data, price)
+	data = u64(data, baseLots)
+	data = u64(data, 0)
+
+	data = append(data, phoenixEventFee)
+	data = u16(data, 2)
+	data = u64(data, 15)
+
+	data = append(data, phoenixEventFillSummary)
+	data = u16(data, 3)
+	data = append(data, make([]byte, 16)...) // client order id
+	data = u64(data, baseLots)
+	data = u64(data, quoteLots)
+	data = u64(data, 15)
+	return data
+}
+
+func TestProcessPhoenixSwaps_SellIntoBid(t *testing.T) {
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	logAuthority, market, userSOL, userUSDC, baseVault, quoteVault, maker := testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), testKey(6), testKey(7)
+	keys := []solana.PublicKey{
+		testPayer,             // 0
+		logAuthority,          // 1
+		market,                // 2
+		userSOL,               // 3
+		userUSDC,              // 4
+		baseVault,             // 5
+		quoteVault,            // 6
+		solana.TokenProgramID, // 7
+		PHOENIX_PROGRAM_ID,    // 8
+	}
+	bidSeq := ^uint64(1234) // bids are stored bit-inverted
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 7, Accounts: []uint16{3, 5, 0}, Data: transferData(1_000_000_000)},
+				{ProgramIDIndex: 7, Accounts: []uint16{6, 4, 6}, Data: transferData(150_000_000)},
+				{ProgramIDIndex: 8, Accounts: []uint16{1}, Data: phoenixLogData(market, testPayer, maker, bidSeq, 150_000, 1_000, 150_000)},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(3, sol, testPayer, "0", 9),
+			tokenBalance(4, usdc, testPayer, "150000000", 6),
+			tokenBalance(5, sol, market, "1000000000", 9),
+			tokenBalance(6, usdc, market, "0", 6),
+		},
+	}
+	p := newTestParser(t, keys,
+		[]solana.CompiledInstruction{{ProgramIDIndex: 8, Accounts: []uint16{8, 1, 2, 0, 3, 4, 5, 6, 7}, Data: []byte{0}}},
+		meta,
+	)
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	if len(swaps) != 3 {
+		t.Fatalf("got %d legs, want event + 2 transfers", len(swaps))
+	}
+	event, ok := swaps[0].Data.(*PhoenixTradeEvent)
+	if !ok {
+		t.Fatalf("first leg is %T, want *PhoenixTradeEvent", swaps[0].Data)
+	}
+	if event.IsBuy || !event.Market.Equals(market) || !event.Taker.Equals(testPayer) {
+		t.Errorf("event = %+v", event)
+	}
+	if len(event.Fills) != 1 || !event.Fills[0].Maker.Equals(maker) || event.Fills[0].PriceInTicks != 150_000 {
+		t.Errorf("fills = %+v", event.Fills)
+	}
+	if event.BaseLotsFilled != 1_000 || event.QuoteLotsFilled != 150_000 || event.FeeInQuoteLots != 15 {
+		t.Errorf("lots = %d/%d fee %d", event.BaseLotsFilled, event.QuoteLotsFilled, event.FeeInQuoteLots)
+	}
+
+	info, err := p.ProcessSwapData(swaps)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if info.TokenInMint.String() != sol || info.TokenInAmount != 1_000_000_000 {
+		t.Errorf("in = %s/%d", info.TokenInMint, info.TokenInAmount)
+	}
+	if info.TokenOutMint.String() != usdc || info.TokenOutAmount != 150_000_000 {
+		t.Errorf("out = %s/%d", info.TokenOutMint, info.TokenOutAmount)
+	}
+	if len(info.AMMs) != 1 || info.AMMs[0] != string(PHOENIX) {
+		t.Errorf("AMMs = %v", info.AMMs)
+	}
+	if len(info.Hops) != 1 || !info.Hops[0].Pool.Equals(market) {
+		t.Errorf("hops = %+v", info.Hops)
+	}
+}
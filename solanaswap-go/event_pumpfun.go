@@ -13,6 +13,9 @@ var (
 	PumpfunCreateEventDiscriminator = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 27, 114, 169, 77, 222, 235, 99, 118}
 )
 
+// PumpfunTradeEvent is the bonding curve's TradeEvent. Reserves are the curve's
+// state after the trade. Fields added by later program versions stay zero when
+// decoding older transactions.
 type PumpfunTradeEvent struct {
 	Mint                 solana.PublicKey
 	SolAmount            uint64
@@ -22,6 +25,51 @@ type PumpfunTradeEvent struct {
 	Timestamp            int64
 	VirtualSolReserves   uint64
 	VirtualTokenReserves uint64
+
+	// since v2
+	RealSolReserves   uint64
+	RealTokenReserves uint64
+
+	// since v3 (creator fees)
+	FeeRecipient          solana.PublicKey
+	FeeBasisPoints        uint64
+	Fee                   uint64
+	Creator               solana.PublicKey
+	CreatorFeeBasisPoints uint64
+	CreatorFee            uint64
+}
+
+// Pump.fun TradeEvent payload sizes; the version is told apart by data length.
+// Anything past the v3 fields (volume tracking, ...) is ignored.
+const (
+	pumpfunTradeEventV1Size = 105
+	pumpfunTradeEventV2Size = pumpfunTradeEventV1Size + 16
+	pumpfunTradeEventV3Size = pumpfunTradeEventV2Size + 96
+)
+
+type pumpfunTradeEventV1 struct {
+	Mint                 solana.PublicKey
+	SolAmount            uint64
+	TokenAmount          uint64
+	IsBuy                bool
+	User                 solana.PublicKey
+	Timestamp            int64
+	VirtualSolReserves   uint64
+	VirtualTokenReserves uint64
+}
+
+type pumpfunTradeEventV2 struct {
+	RealSolReserves   uint64
+	RealTokenReserves uint64
+}
+
+type pumpfunTradeEventV3 struct {
+	FeeRecipient          solana.PublicKey
+	FeeBasisPoints        uint64
+	Fee                   uint64
+	Creator               solana.PublicKey
+	CreatorFeeBasisPoints uint64
+	CreatorFee            uint64
 }
 
 type PumpfunCreateEvent struct {
@@ -31,6 +79,7 @@ type PumpfunCreateEvent struct {
 	Mint         solana.PublicKey
 	BondingCurve solana.PublicKey
 	User         solana.PublicKey
+	Creator      solana.PublicKey // since creator fees; zero in older events
 }
 
 func (p *Parser) processPumpfunSwaps(instructionIndex int) []SwapData {
@@ -53,7 +102,35 @@ func (p *Parser) processPumpfunSwaps(instructionIndex int) []SwapData {
 	return swaps
 }
 
+// PumpfunTradeEvents returns every bonding-curve TradeEvent the transaction
+// emitted, in execution order, whoever invoked the curve: a direct trade, a
+// router hop (Jupiter, OKX) or a trading bot.
+func (p *Parser) PumpfunTradeEvents() []*PumpfunTradeEvent {
+	var events []*PumpfunTradeEvent
+	for _, set := range p.txMeta.InnerInstructions {
+		for _, ri := range set.Instructions {
+			ci := p.convertRPCToSolanaInstruction(ri)
+			if int(ci.ProgramIDIndex) >= len(p.allAccountKeys) || !p.isPumpFunTradeEventInstruction(ci) {
+				continue
+			}
+			trade, err := handlePumpfunTradeEvent(ag_binary.NewBorshDecoder(ci.Data[16:]))
+			if err != nil {
+				p.Log.Errorf("error processing Pumpfun trade event: %s", err)
+				continue
+			}
+			events = append(events, trade)
+		}
+	}
+	return events
+}
+
+// processPumpfunAMMSwaps prefers PumpSwap's Buy/SellEvents; the transfers under
+// the instruction also carry the protocol and creator fees, so they are only a
+// fallback for instructions without events.
 func (p *Parser) processPumpfunAMMSwaps(instructionIndex int) []SwapData {
+	if events := p.pumpSwapEvents(instructionIndex); len(events) > 0 {
+		return events
+	}
 	var swaps []SwapData
 	for _, innerInstructionSet := range p.txMeta.InnerInstructions {
 		if innerInstructionSet.Index == uint16(instructionIndex) {
@@ -87,10 +164,63 @@ func (p *Parser) parsePumpfunTradeEventInstruction(instruction solana.CompiledIn
 }
 
 func handlePumpfunTradeEvent(decoder *ag_binary.Decoder) (*PumpfunTradeEvent, error) {
-	var trade PumpfunTradeEvent
-	if err := decoder.Decode(&trade); err != nil {
+	size := decoder.Remaining()
+	var v1 pumpfunTradeEventV1
+	if err := decoder.Decode(&v1); err != nil {
 		return nil, fmt.Errorf("error unmarshaling TradeEvent: %s", err)
 	}
+	trade := PumpfunTradeEvent{
+		Mint:                 v1.Mint,
+		SolAmount:            v1.SolAmount,
+		TokenAmount:          v1.TokenAmount,
+		IsBuy:                v1.IsBuy,
+		User:                 v1.User,
+		Timestamp:            v1.Timestamp,
+		VirtualSolReserves:   v1.VirtualSolReserves,
+		VirtualTokenReserves: v1.VirtualTokenReserves,
+	}
 
+	if size >= pumpfunTradeEventV2Size {
+		var v2 pumpfunTradeEventV2
+		if err := decoder.Decode(&v2); err != nil {
+			return nil, fmt.Errorf("error unmarshaling TradeEvent v2 fields: %s", err)
+		}
+		trade.RealSolReserves = v2.RealSolReserves
+		trade.RealTokenReserves = v2.RealTokenReserves
+	}
+	if size >= pumpfunTradeEventV3Size {
+		var v3 pumpfunTradeEventV3
+		if err := decoder.Decode(&v3); err != nil {
+			return nil, fmt.Errorf("error unmarshaling TradeEvent v3 fields: %s", err)
+		}
+		trade.FeeRecipient = v3.FeeRecipient
+		trade.FeeBasisPoints = v3.FeeBasisPoints
+		trade.Fee = v3.Fee
+		trade.Creator = v3.Creator
+		trade.CreatorFeeBasisPoints = v3.CreatorFeeBasisPoints
+		trade.CreatorFee = v3.CreatorFee
+	}
 	return &trade, nil
 }
+
+// pumpfunFees reports the protocol and creator fees of the trade events as SOL
+// commissions.
+func pumpfunFees(swapDatas []SwapData) []Commission {
+	var fees []Commission
+	for _, sd := range swapDatas {
+		v, ok := sd.Data.(*PumpfunTradeEvent)
+		if !ok {
+			continue
+		}
+		for _, c := range []Commission{
+			{Source: "pumpfun_protocol", Recipient: v.FeeRecipient, Amount: v.Fee},
+			{Source: "pumpfun_creator", Recipient: v.Creator, Amount: v.CreatorFee},
+		} {
+			if c.Amount > 0 {
+				c.Mint = NATIVE_SOL_MINT_PROGRAM_ID
+				fees = append(fees, c)
+			}
+		}
+	}
+	return fees
+}
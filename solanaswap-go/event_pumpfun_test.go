@@ -0,0 +1,90 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// pumpfunTradeEventData encodes a TradeEvent payload of the given version.
+func pumpfunTradeEventData(version int) []byte {
+	u64 := binary.LittleEndian.AppendUint64
+	data := append([]byte{}, testKey(1).Bytes()...) // mint
+	data = u64(data, 1_000_000_000)                 // sol_amount
+	data = u64(data, 35_000_000_000)                // token_amount
+	data = append(data, 1)                          // is_buy
+	data = append(data, testPayer.Bytes()...)
+	data = u64(data, 1_700_000_000)
+	data = u64(data, 31_000_000_000)        // virtual_sol_reserves
+	data = u64(data, 1_038_000_000_000_000) // virtual_token_reserves
+	if version >= 2 {
+		data = u64(data, 1_000_000_000)
+		data = u64(data, 758_000_000_000_000)
+	}
+	if version >= 3 {
+		data = append(data, testKey(2).Bytes()...)
+		data = u64(data, 95)
+		data = u64(data, 9_500_000)
+		data = append(data, testKey(3).Bytes()...)
+		data = u64(data, 5)
+		data = u64(data, 500_000)
+		data = append(data, 1)                   // track_volume (v4, ignored)
+		data = append(data, make([]byte, 32)...) // volume counters
+	}
+	return data
+}
+
+func TestHandlePumpfunTradeEvent_Versions(t *testing.T) {
+	for _, version := range []int{1, 2, 3} {
+		trade, err := handlePumpfunTradeEvent(ag_binary.NewBorshDecoder(pumpfunTradeEventData(version)))
+		if err != nil {
+			t.Fatalf("v%d: %v", version, err)
+		}
+		if !trade.IsBuy || !trade.User.Equals(testPayer) || trade.VirtualSolReserves != 31_000_000_000 {
+			t.Errorf("v%d: base fields = %+v", version, trade)
+		}
+		if got := trade.RealTokenReserves != 0; got != (version >= 2) {
+			t.Errorf("v%d: RealTokenReserves = %d", version, trade.RealTokenReserves)
+		}
+		if version < 3 {
+			if trade.Fee != 0 || !trade.Creator.IsZero() {
+				t.Errorf("v%d: fee fields set: %+v", version, trade)
+			}
+			continue
+		}
+		if !trade.FeeRecipient.Equals(testKey(2)) || trade.FeeBasisPoints != 95 || trade.Fee != 9_500_000 {
+			t.Errorf("v3: protocol fee = %s/%d/%d", trade.FeeRecipient, trade.FeeBasisPoints, trade.Fee)
+		}
+		if !trade.Creator.Equals(testKey(3)) || trade.CreatorFeeBasisPoints != 5 || trade.CreatorFee != 500_000 {
+			t.Errorf("v3: creator fee = %s/%d/%d", trade.Creator, trade.CreatorFeeBasisPoints, trade.CreatorFee)
+		}
+		fees := pumpfunFees([]SwapData{{Type: PUMP_FUN, Data: trade}})
+		if len(fees) != 2 || fees[1].Source != "pumpfun_creator" || fees[1].Amount != 500_000 {
+			t.Errorf("v3: commissions = %+v", fees)
+		}
+	}
+}
+
+func TestPumpfunTradeEvents_RoutedTrade(t *testing.T) {
+	keys := []solana.PublicKey{testPayer, JUPITER_PROGRAM_ID, PUMP_FUN_PROGRAM_ID, testKey(4)}
+	event := append(append([]byte{}, PumpfunTradeEventDiscriminator[:]...), pumpfunTradeEventData(2)...)
+	meta := &rpc.TransactionMeta{InnerInstructions: []rpc.InnerInstruction{{
+		Index: 0,
+		Instructions: []rpc.CompiledInstruction{
+			{ProgramIDIndex: 2, Accounts: []uint16{3, 0}, Data: []byte{0x66}, StackHeight: 2},
+			{ProgramIDIndex: 2, Accounts: []uint16{3}, Data: event, StackHeight: 3},
+		},
+	}}}
+	p := newTestParser(t, keys, []solana.CompiledInstruction{{ProgramIDIndex: 1}}, meta)
+
+	events := p.PumpfunTradeEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if ev := events[0]; !ev.Mint.Equals(testKey(1)) || ev.RealTokenReserves != 758_000_000_000_000 {
+		t.Errorf("event = %+v", ev)
+	}
+}
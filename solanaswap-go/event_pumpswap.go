@@ -0,0 +1,176 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"fmt"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+var (
+	PumpSwapBuyEventDiscriminator  = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 103, 244, 82, 31, 44, 245, 119, 119}
+	PumpSwapSellEventDiscriminator = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 62, 47, 55, 10, 165, 3, 220, 42}
+)
+
+// PumpSwapTradeEvent is a PumpSwap (pump.fun AMM) BuyEvent or SellEvent. Fees
+// are charged in the quote mint.
+type PumpSwapTradeEvent struct {
+	IsBuy     bool
+	Timestamp int64
+	Pool      solana.PublicKey
+	User      solana.PublicKey
+	BaseMint  solana.PublicKey
+	QuoteMint solana.PublicKey
+
+	// BaseAmount and QuoteAmount are what the user traded: on buys the quote
+	// paid including every fee, on sells the quote received after them.
+	BaseAmount  uint64
+	QuoteAmount uint64
+
+	LpFee             uint64
+	LpFeeBps          uint64
+	ProtocolFee       uint64
+	ProtocolFeeBps    uint64
+	CoinCreatorFee    uint64
+	CoinCreatorFeeBps uint64
+
+	ProtocolFeeRecipient solana.PublicKey
+	CoinCreator          solana.PublicKey // zero for pools created before creator fees
+
+	// Pool reserves the trade was priced against (before the trade).
+	PoolBaseReserves  uint64
+	PoolQuoteReserves uint64
+}
+
+// pumpSwapEventFields is the layout BuyEvent and SellEvent share; QuoteLimit
+// is max_quote_amount_in / min_quote_amount_out and QuoteAmount the quote
+// amount before LP fees.
+type pumpSwapEventFields struct {
+	Timestamp                        int64
+	BaseAmount                       uint64
+	QuoteLimit                       uint64
+	UserBaseTokenReserves            uint64
+	UserQuoteTokenReserves           uint64
+	PoolBaseTokenReserves            uint64
+	PoolQuoteTokenReserves           uint64
+	QuoteAmount                      uint64
+	LpFeeBasisPoints                 uint64
+	LpFee                            uint64
+	ProtocolFeeBasisPoints           uint64
+	ProtocolFee                      uint64
+	QuoteAmountWithLpFee             uint64
+	UserQuoteAmount                  uint64
+	Pool                             solana.PublicKey
+	User                             solana.PublicKey
+	UserBaseTokenAccount             solana.PublicKey
+	UserQuoteTokenAccount            solana.PublicKey
+	ProtocolFeeRecipient             solana.PublicKey
+	ProtocolFeeRecipientTokenAccount solana.PublicKey
+}
+
+// pumpSwapCreatorFields were appended to both events with coin creator fees.
+type pumpSwapCreatorFields struct {
+	CoinCreator               solana.PublicKey
+	CoinCreatorFeeBasisPoints uint64
+	CoinCreatorFee            uint64
+}
+
+func isPumpSwapEvent(data []byte) bool {
+	return len(data) >= 16 &&
+		(bytes.Equal(data[:16], PumpSwapBuyEventDiscriminator[:]) || bytes.Equal(data[:16], PumpSwapSellEventDiscriminator[:]))
+}
+
+// pumpSwapEvents decodes the Buy/SellEvents under the instruction at index. The
+// mints come from the buy/sell instruction that emitted each event.
+func (p *Parser) pumpSwapEvents(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	var invocation *solana.CompiledInstruction
+	if outer := p.txInfo.Message.Instructions[instructionIndex]; p.allAccountKeys[outer.ProgramIDIndex].Equals(PUMPFUN_AMM_PROGRAM_ID) {
+		invocation = &outer
+	}
+	for _, inst := range p.getInnerInstructions(instructionIndex) {
+		if !p.allAccountKeys[inst.ProgramIDIndex].Equals(PUMPFUN_AMM_PROGRAM_ID) {
+			continue
+		}
+		if !isPumpSwapEvent(inst.Data) {
+			inst := inst
+			invocation = &inst
+			continue
+		}
+		event, err := parsePumpSwapEvent(inst.Data)
+		if err != nil {
+			p.Log.Errorf("error processing PumpSwap event: %s", err)
+			continue
+		}
+		if invocation != nil && len(invocation.Accounts) >= 5 {
+			event.BaseMint = p.allAccountKeys[invocation.Accounts[3]]
+			event.QuoteMint = p.allAccountKeys[invocation.Accounts[4]]
+		}
+		swaps = append(swaps, SwapData{Type: PUMP_FUN, Data: event})
+	}
+	return swaps
+}
+
+func parsePumpSwapEvent(data []byte) (*PumpSwapTradeEvent, error) {
+	decoder := ag_binary.NewBorshDecoder(data[16:])
+	var fields pumpSwapEventFields
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("error unmarshaling PumpSwap event: %s", err)
+	}
+	var creator pumpSwapCreatorFields
+	if decoder.Remaining() >= 48 {
+		if err := decoder.Decode(&creator); err != nil {
+			return nil, fmt.Errorf("error unmarshaling PumpSwap creator fee: %s", err)
+		}
+	}
+	return &PumpSwapTradeEvent{
+		IsBuy:                bytes.Equal(data[:16], PumpSwapBuyEventDiscriminator[:]),
+		Timestamp:            fields.Timestamp,
+		Pool:                 fields.Pool,
+		User:                 fields.User,
+		BaseAmount:           fields.BaseAmount,
+		QuoteAmount:          fields.UserQuoteAmount,
+		LpFee:                fields.LpFee,
+		LpFeeBps:             fields.LpFeeBasisPoints,
+		ProtocolFee:          fields.ProtocolFee,
+		ProtocolFeeBps:       fields.ProtocolFeeBasisPoints,
+		CoinCreatorFee:       creator.CoinCreatorFee,
+		CoinCreatorFeeBps:    creator.CoinCreatorFeeBasisPoints,
+		ProtocolFeeRecipient: fields.ProtocolFeeRecipient,
+		CoinCreator:          creator.CoinCreator,
+		PoolBaseReserves:     fields.PoolBaseTokenReserves,
+		PoolQuoteReserves:    fields.PoolQuoteTokenReserves,
+	}, nil
+}
+
+// sides returns the event as input and output mint/amount from the user's view.
+func (e *PumpSwapTradeEvent) sides() (inMint solana.PublicKey, inAmount uint64, outMint solana.PublicKey, outAmount uint64) {
+	if e.IsBuy {
+		return e.QuoteMint, e.QuoteAmount, e.BaseMint, e.BaseAmount
+	}
+	return e.BaseMint, e.BaseAmount, e.QuoteMint, e.QuoteAmount
+}
+
+// pumpSwapFees reports the LP, protocol and coin creator fees of the events as
+// commissions in the quote mint.
+func pumpSwapFees(swapDatas []SwapData) []Commission {
+	var fees []Commission
+	for _, sd := range swapDatas {
+		v, ok := sd.Data.(*PumpSwapTradeEvent)
+		if !ok {
+			continue
+		}
+		for _, c := range []Commission{
+			{Source: "pumpswap_lp", Recipient: v.Pool, Amount: v.LpFee},
+			{Source: "pumpswap_protocol", Recipient: v.ProtocolFeeRecipient, Amount: v.ProtocolFee},
+			{Source: "pumpswap_creator", Recipient: v.CoinCreator, Amount: v.CoinCreatorFee},
+		} {
+			if c.Amount > 0 {
+				c.Mint = v.QuoteMint
+				fees = append(fees, c)
+			}
+		}
+	}
+	return fees
+}
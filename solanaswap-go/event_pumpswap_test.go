@@ -0,0 +1,109 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestProcessPumpfunAMMSwaps_PrefersBuyEvent(t *testing.T) {
+	sol := NATIVE_SOL_MINT_PROGRAM_ID
+	bonk := solana.MustPublicKeyFromBase58(testBonkMint)
+	pool, global, userBase, userQuote, poolBase, poolQuote, feeRecipient, feeAccount, creator, creatorVault :=
+		testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), testKey(6), testKey(7), testKey(8), testKey(9), testKey(10)
+	keys := []solana.PublicKey{
+		testPayer,              // 0
+		pool,                   // 1
+		global,                 // 2
+		bonk,                   // 3
+		sol,                    // 4
+		userBase,               // 5
+		userQuote,              // 6
+		poolBase,               // 7
+		poolQuote,              // 8
+		feeAccount,             // 9
+		creatorVault,           // 10
+		solana.TokenProgramID,  // 11
+		PUMPFUN_AMM_PROGRAM_ID, // 12
+	}
+
+	u64s := []uint64{
+		1_700_000_000, // timestamp
+		42_000,        // base_amount_out
+		1_100_000,     // max_quote_amount_in
+		0, 0,          // user reserves
+		5_000_000,   // pool base reserves
+		120_000_000, // pool quote reserves
+		1_000_000,   // quote_amount_in
+		20, 2_000,   // lp fee bps, lp fee
+		5, 500, // protocol fee bps, protocol fee
+		1_002_000, // quote_amount_in_with_lp_fee
+		1_003_000, // user_quote_amount_in
+	}
+	event := append([]byte{}, PumpSwapBuyEventDiscriminator[:]...)
+	for _, v := range u64s {
+		event = binary.LittleEndian.AppendUint64(event, v)
+	}
+	for _, k := range []solana.PublicKey{pool, testPayer, userBase, userQuote, feeRecipient, feeAccount, creator} {
+		event = append(event, k.Bytes()...)
+	}
+	event = binary.LittleEndian.AppendUint64(event, 5)
+	event = binary.LittleEndian.AppendUint64(event, 500)
+
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 11, Accounts: []uint16{6, 8, 0}, Data: transferData(1_002_000)},
+				{ProgramIDIndex: 11, Accounts: []uint16{7, 5, 1}, Data: transferData(42_000)},
+				{ProgramIDIndex: 11, Accounts: []uint16{6, 9, 0}, Data: transferData(500)},
+				{ProgramIDIndex: 11, Accounts: []uint16{6, 10, 0}, Data: transferData(500)},
+				{ProgramIDIndex: 12, Accounts: []uint16{12}, Data: event},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(5, testBonkMint, testPayer, "42000", 5),
+			tokenBalance(6, sol.String(), testPayer, "0", 9),
+			tokenBalance(7, testBonkMint, pool, "4958000", 5),
+			tokenBalance(8, sol.String(), pool, "121002000", 9),
+		},
+	}
+	buy := append(pumpBuyDisc[:], make([]byte, 16)...)
+	p := newTestParser(t, keys,
+		[]solana.CompiledInstruction{{ProgramIDIndex: 12, Accounts: []uint16{1, 0, 2, 3, 4, 5, 6, 7, 8}, Data: buy}},
+		meta,
+	)
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	if len(swaps) != 1 {
+		t.Fatalf("got %d legs, want only the BuyEvent", len(swaps))
+	}
+	info, err := p.ProcessSwapData(swaps)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if !info.TokenInMint.Equals(sol) || info.TokenInAmount != 1_003_000 {
+		t.Errorf("in = %s/%d, want SOL/1003000", info.TokenInMint, info.TokenInAmount)
+	}
+	if !info.TokenOutMint.Equals(bonk) || info.TokenOutAmount != 42_000 || info.TokenOutDecimals != 5 {
+		t.Errorf("out = %s/%d/%d", info.TokenOutMint, info.TokenOutAmount, info.TokenOutDecimals)
+	}
+	if info.Method != MethodPumpSwapEvent {
+		t.Errorf("Method = %s", info.Method)
+	}
+	if len(info.Fees.Commissions) != 3 {
+		t.Fatalf("commissions = %+v", info.Fees.Commissions)
+	}
+	if c := info.Fees.Commissions[2]; c.Source != "pumpswap_creator" || !c.Recipient.Equals(creator) || c.Amount != 500 || !c.Mint.Equals(sol) {
+		t.Errorf("creator fee = %+v", c)
+	}
+	if len(info.Hops) != 1 || !info.Hops[0].Pool.Equals(pool) ||
+		info.Hops[0].InputReserve != 120_000_000 || info.Hops[0].OutputReserve != 5_000_000 {
+		t.Errorf("hops = %+v", info.Hops)
+	}
+}
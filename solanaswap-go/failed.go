@@ -0,0 +1,260 @@
+package solanaswapgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// FailureReason classifies why a swap transaction failed.
+type FailureReason string
+
+const (
+	FailureSlippage          FailureReason = "slippage_exceeded"
+	FailureInsufficientFunds FailureReason = "insufficient_funds"
+	FailureComputeExceeded   FailureReason = "compute_budget_exceeded"
+	FailureOther             FailureReason = "other"
+)
+
+// FailedSwap is the swap a failed transaction attempted.
+type FailedSwap struct {
+	Signers    []solana.PublicKey
+	Signatures []solana.Signature
+	Timestamp  time.Time
+	Slot       uint64
+	Trader     solana.PublicKey
+	FeePayer   solana.PublicKey
+
+	// InstructionIndex and ProgramID identify the outer instruction that carried
+	// the swap: the venue itself, a router, or a bot wrapping the venue.
+	InstructionIndex int
+	ProgramID        solana.PublicKey
+	Protocol         string // registered decoder name ("jupiter", "raydium", "bots", ...)
+	Bot              string // "bananagun", "maestro", ... when a trading bot sent it
+
+	// Args holds the declared intent; nil when the arguments couldn't be decoded.
+	Args *SwapArgs
+
+	Reason FailureReason
+	// FailedProgramID is the program that raised the error (innermost failing
+	// program from the logs); ErrorCode is its custom error code, if any.
+	FailedProgramID solana.PublicKey
+	ErrorCode       *uint32
+	Error           string // meta.Err as reported by the RPC node
+
+	Fees Fees
+}
+
+// IsFailed reports whether the transaction failed on chain (meta.Err is set).
+func (p *Parser) IsFailed() bool {
+	return p.txMeta.Err != nil
+}
+
+// ParseFailedSwap decodes the swap a failed transaction attempted. It returns an
+// error if the transaction succeeded or carries no recognizable swap instruction.
+func (p *Parser) ParseFailedSwap() (*FailedSwap, error) {
+	if !p.IsFailed() {
+		return nil, fmt.Errorf("transaction did not fail")
+	}
+
+	failedIndex, code := p.instructionError()
+	candidates := make([]int, 0, len(p.txInfo.Message.Instructions))
+	if failedIndex >= 0 && failedIndex < len(p.txInfo.Message.Instructions) {
+		candidates = append(candidates, failedIndex)
+	}
+	for i := range p.txInfo.Message.Instructions {
+		if i != failedIndex {
+			candidates = append(candidates, i)
+		}
+	}
+
+	for _, i := range candidates {
+		outer := p.txInfo.Message.Instructions[i]
+		progID := p.allAccountKeys[outer.ProgramIDIndex]
+		d := p.decoderFor(progID)
+		if d == nil {
+			continue
+		}
+
+		trader := p.resolveTrader()
+		failed := &FailedSwap{
+			Signers:          []solana.PublicKey{trader},
+			Signatures:       p.txInfo.Signatures,
+			Slot:             p.slot,
+			Trader:           trader,
+			FeePayer:         p.FeePayer(),
+			InstructionIndex: i,
+			ProgramID:        progID,
+			Protocol:         d.Name(),
+			Bot:              botCommissionSources[progID],
+			ErrorCode:        code,
+			Error:            errorString(p.txMeta.Err),
+			Fees:             p.buildFees(nil, i),
+		}
+		if p.blockTime != nil {
+			failed.Timestamp = p.blockTime.Time()
+		}
+		failed.Args = p.swapArgsFor(i)
+		failed.FailedProgramID, failed.Reason = p.classifyFailure(code)
+		return failed, nil
+	}
+	return nil, fmt.Errorf("no swap instruction found in failed transaction")
+}
+
+// instructionError extracts the failing instruction index and custom error code
+// from meta.Err ({"InstructionError":[2,{"Custom":6001}]}); -1/nil when absent.
+func (p *Parser) instructionError() (int, *uint32) {
+	m, ok := p.txMeta.Err.(map[string]interface{})
+	if !ok {
+		return -1, nil
+	}
+	ie, ok := m["InstructionError"].([]interface{})
+	if !ok || len(ie) != 2 {
+		return -1, nil
+	}
+	index := -1
+	if v, ok := toInt64(ie[0]); ok {
+		index = int(v)
+	}
+	if detail, ok := ie[1].(map[string]interface{}); ok {
+		if v, ok := toInt64(detail["Custom"]); ok {
+			c := uint32(v)
+			return index, &c
+		}
+	}
+	return index, nil
+}
+
+// Custom error codes meaning "output below minimum / input above maximum".
+var slippageErrorCodes = map[solana.PublicKey][]uint32{
+	JUPITER_PROGRAM_ID:                        {6001},       // SlippageToleranceExceeded
+	RAYDIUM_V4_PROGRAM_ID:                     {30},         // ExceededSlippage
+	RAYDIUM_CPMM_PROGRAM_ID:                   {6005},       // ExceededSlippage
+	RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID: {6022, 6023}, // TooLittleOutputReceived, TooMuchInputPaid
+	ORCA_PROGRAM_ID:                           {6036, 6037}, // AmountOutBelowMinimum, AmountInAboveMaximum
+	METEORA_PROGRAM_ID:                        {6003},       // ExceededAmountSlippageTolerance
+	PUMP_FUN_PROGRAM_ID:                       {6002, 6003}, // TooMuchSolRequired, TooLittleSolReceived
+	PUMPFUN_AMM_PROGRAM_ID:                    {6004},       // ExceededSlippage
+}
+
+var (
+	invokeRe        = regexp.MustCompile(`^Program (\w+) invoke \[\d+\]$`)
+	successRe       = regexp.MustCompile(`^Program (\w+) success$`)
+	failedProgramRe = regexp.MustCompile(`^Program (\w+) failed: (.*)$`)
+)
+
+// classifyFailure maps the error to a FailureReason, using the program that
+// raised it when the logs name one and falling back on that invocation's log
+// text otherwise.
+func (p *Parser) classifyFailure(code *uint32) (solana.PublicKey, FailureReason) {
+	failedProgram, failedLogs := p.failedInvocationLogs()
+
+	if s, ok := p.txMeta.Err.(string); ok && strings.HasPrefix(s, "InsufficientFunds") {
+		return failedProgram, FailureInsufficientFunds
+	}
+	if m, ok := p.txMeta.Err.(map[string]interface{}); ok {
+		if _, ok := m["InsufficientFundsForRent"]; ok {
+			return failedProgram, FailureInsufficientFunds
+		}
+	}
+
+	if code != nil && !failedProgram.IsZero() {
+		for _, c := range slippageErrorCodes[failedProgram] {
+			if c == *code {
+				return failedProgram, FailureSlippage
+			}
+		}
+		// SPL Token InsufficientFunds / System ResultWithNegativeLamports
+		if *code == 1 && (p.isTokenProgram(failedProgram) || failedProgram.Equals(solana.SystemProgramID)) {
+			return failedProgram, FailureInsufficientFunds
+		}
+	}
+
+	// Only the failing invocation's own lines: other programs in the same
+	// transaction may well log "slippage" without being the cause.
+	text := strings.ToLower(strings.Join(failedLogs, "\n"))
+	switch {
+	case strings.Contains(text, "slippage"),
+		strings.Contains(text, "too little"),
+		strings.Contains(text, "too much"),
+		strings.Contains(text, "below minimum"),
+		strings.Contains(text, "above maximum"):
+		return failedProgram, FailureSlippage
+	case strings.Contains(text, "insufficient funds"),
+		strings.Contains(text, "insufficient lamports"):
+		return failedProgram, FailureInsufficientFunds
+	case strings.Contains(text, "exceeded cus meter"),
+		strings.Contains(text, "computational budget exceeded"):
+		return failedProgram, FailureComputeExceeded
+	}
+	return failedProgram, FailureOther
+}
+
+// failedInvocationLogs returns the innermost failing program (the first
+// "failed" line) and the lines it logged itself, its failure message included;
+// lines of the programs it invoked are not part of them.
+func (p *Parser) failedInvocationLogs() (solana.PublicKey, []string) {
+	var frames [][]string
+	for _, line := range p.txMeta.LogMessages {
+		if invokeRe.MatchString(line) {
+			frames = append(frames, nil)
+			continue
+		}
+		if successRe.MatchString(line) {
+			if len(frames) > 0 {
+				frames = frames[:len(frames)-1]
+			}
+			continue
+		}
+		if m := failedProgramRe.FindStringSubmatch(line); len(m) == 3 {
+			pk, err := solana.PublicKeyFromBase58(m[1])
+			if err != nil {
+				continue
+			}
+			var own []string
+			if len(frames) > 0 {
+				own = frames[len(frames)-1]
+			}
+			return pk, append(own, m[2])
+		}
+		if len(frames) > 0 {
+			frames[len(frames)-1] = append(frames[len(frames)-1], line)
+		}
+	}
+	return solana.PublicKey{}, nil
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int64(n), true
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func errorString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,118 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// raydiumV4SwapBaseIn builds the 18-account swapBaseIn instruction by testPayer.
+// Keys: 0 payer, 1..17 swap accounts (5/6 pool vaults, 15/16 user source/destination),
+// 18 token program, 19 Raydium V4.
+func raydiumV4SwapBaseIn(amountIn, minOut uint64) ([]solana.PublicKey, solana.CompiledInstruction) {
+	keys := []solana.PublicKey{testPayer}
+	for i := byte(1); i <= 17; i++ {
+		keys = append(keys, testKey(i))
+	}
+	keys = append(keys, solana.TokenProgramID, RAYDIUM_V4_PROGRAM_ID)
+
+	accounts := []uint16{18}
+	for i := uint16(1); i <= 16; i++ {
+		accounts = append(accounts, i)
+	}
+	accounts = append(accounts, 0) // owner
+
+	data := make([]byte, 17)
+	data[0] = 9 // swapBaseIn
+	binary.LittleEndian.PutUint64(data[1:], amountIn)
+	binary.LittleEndian.PutUint64(data[9:], minOut)
+	return keys, solana.CompiledInstruction{ProgramIDIndex: 19, Accounts: accounts, Data: data}
+}
+
+func TestParseFailedSwap_RaydiumV4Slippage(t *testing.T) {
+	keys, swap := raydiumV4SwapBaseIn(1_000_000, 42_000)
+
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	meta := &rpc.TransactionMeta{
+		Err: map[string]interface{}{
+			"InstructionError": []interface{}{float64(0), map[string]interface{}{"Custom": float64(30)}},
+		},
+		LogMessages: []string{
+			"Program 675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8 invoke [1]",
+			"Program log: Error: exceeds desired slippage limit",
+			"Program 675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8 failed: custom program error: 0x1e",
+		},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(5, sol, testKey(2), "1000", 9),
+			tokenBalance(6, testBonkMint, testKey(2), "1000", 5),
+			tokenBalance(16, testBonkMint, testPayer, "0", 5),
+		},
+	}
+	p := newTestParser(t, keys,
+		[]solana.CompiledInstruction{swap},
+		meta,
+	)
+
+	if !p.IsFailed() {
+		t.Fatal("IsFailed = false")
+	}
+	if swaps, _ := p.ParseTransaction(); len(swaps) != 0 {
+		t.Fatalf("ParseTransaction returned legs for a failed tx: %+v", swaps)
+	}
+
+	failed, err := p.ParseFailedSwap()
+	if err != nil {
+		t.Fatalf("ParseFailedSwap: %v", err)
+	}
+	if failed.Reason != FailureSlippage {
+		t.Errorf("Reason = %s, want %s", failed.Reason, FailureSlippage)
+	}
+	if failed.ErrorCode == nil || *failed.ErrorCode != 30 || !failed.FailedProgramID.Equals(RAYDIUM_V4_PROGRAM_ID) {
+		t.Errorf("error = %v from %s", failed.ErrorCode, failed.FailedProgramID)
+	}
+	if failed.Protocol != PROTOCOL_RAYDIUM || failed.InstructionIndex != 0 {
+		t.Errorf("protocol/index = %s/%d", failed.Protocol, failed.InstructionIndex)
+	}
+	args := failed.Args
+	if args == nil {
+		t.Fatal("Args = nil")
+	}
+	// The user's WSOL account isn't in the balances; the input side comes from the vaults.
+	if args.InputMint.String() != sol || args.OutputMint.String() != testBonkMint {
+		t.Errorf("mints = %s → %s", args.InputMint, args.OutputMint)
+	}
+	if args.AmountIn != 1_000_000 || args.MinAmountOut != 42_000 || args.ExactOut {
+		t.Errorf("args = %+v", args)
+	}
+}
+
+func TestParseFailedSwap_OtherProgramLogsIgnored(t *testing.T) {
+	keys, swap := raydiumV4SwapBaseIn(1_000_000, 42_000)
+	keys = append(keys, testKey(40)) // 20: some other program
+	meta := &rpc.TransactionMeta{
+		Err: map[string]interface{}{
+			"InstructionError": []interface{}{float64(1), map[string]interface{}{"Custom": float64(7)}},
+		},
+		LogMessages: []string{
+			"Program " + testKey(40).String() + " invoke [1]",
+			"Program log: slippage set to 50 bps",
+			"Program " + testKey(40).String() + " success",
+			"Program 675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8 invoke [1]",
+			"Program log: Error: invalid pool status",
+			"Program 675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8 failed: custom program error: 0x7",
+		},
+	}
+	p := newTestParser(t, keys,
+		[]solana.CompiledInstruction{{ProgramIDIndex: 20}, swap},
+		meta,
+	)
+	failed, err := p.ParseFailedSwap()
+	if err != nil {
+		t.Fatalf("ParseFailedSwap: %v", err)
+	}
+	if failed.Reason != FailureOther || !failed.FailedProgramID.Equals(RAYDIUM_V4_PROGRAM_ID) {
+		t.Errorf("reason = %s from %s, want %s", failed.Reason, failed.FailedProgramID, FailureOther)
+	}
+}
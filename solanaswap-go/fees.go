@@ -0,0 +1,229 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Fees is what a swap cost on top of the traded amounts. SOL figures are in lamports.
+//
+// NetworkFee, PriorityFee and JitoTip are paid once per transaction, so every
+// SwapInfo returned by ProcessAllSwaps for the same transaction repeats them;
+// Commissions only cover the instruction the swap came from.
+type Fees struct {
+	// NetworkFee is meta.Fee: base fee plus the priority fee actually charged.
+	NetworkFee uint64
+	// PriorityFee is ComputeUnitLimit × ComputeUnitPrice, rounded up.
+	PriorityFee      uint64
+	ComputeUnitLimit uint32
+	ComputeUnitPrice uint64 // micro-lamports per compute unit
+	JitoTip          uint64
+	Commissions      []Commission
+}
+
+// Commission is a fee taken by a router, a frontend, a trading bot or the
+// venue itself (Pump.fun and PumpSwap protocol and creator fees, PumpSwap LP fee).
+type Commission struct {
+	Source    string // "okx", "jupiter", "bananagun", "pumpswap_protocol", ...
+	Recipient solana.PublicKey
+	Mint      solana.PublicKey
+	Amount    uint64
+}
+
+// Jito tip payment accounts.
+var JITO_TIP_ACCOUNTS = []solana.PublicKey{
+	solana.MustPublicKeyFromBase58("96gYZGLnJYVFmbjzopPSU6QiEV5fGqZNyN9nmNhvrZU5"),
+	solana.MustPublicKeyFromBase58("HFqU5x63VTqvQss8hp11i4wVV8bD44PvwucfZ2bU7gRe"),
+	solana.MustPublicKeyFromBase58("Cw8CFyM9FkoMi7K7Crf6HNQqf4uEMzpKw6QNghXLvLkY"),
+	solana.MustPublicKeyFromBase58("ADaUMid9yfUytqMBgopwjb2DTLSokTSzL1zt6iGPaS49"),
+	solana.MustPublicKeyFromBase58("DfXygSm4jCyNCybVYYK6DwvWqjKee8pbDmJGcLWNDXjh"),
+	solana.MustPublicKeyFromBase58("ADuUkR4vqLUMWXxW9gh6D6L8pMSawimctcNZ5pGwDcEt"),
+	solana.MustPublicKeyFromBase58("DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL"),
+	solana.MustPublicKeyFromBase58("3AVi9Tg9Uo68tJfuvoKvqKNWKkC5wPdSSdeBnizKZ6jT"),
+}
+
+// Anchor event discriminator for Jupiter's FeeEvent (platform fee), emitted via self-CPI.
+var JupiterFeeEventDiscriminator = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 73, 79, 78, 127, 184, 213, 13, 220}
+
+var botCommissionSources = map[solana.PublicKey]string{
+	BANANA_GUN_PROGRAM_ID: "bananagun",
+	MINTECH_PROGRAM_ID:    "mintech",
+	BLOOM_PROGRAM_ID:      "bloom",
+	NOVA_PROGRAM_ID:       "nova",
+	MAESTRO_PROGRAM_ID:    "maestro",
+}
+
+const (
+	computeBudgetSetLimit = 2
+	computeBudgetSetPrice = 3
+
+	defaultComputeUnitsPerInstruction = 200_000
+	maxComputeUnitLimit               = 1_400_000
+)
+
+// buildFees collects the fees for a swap. scope is the outer instruction the
+// swap belongs to, or -1 for the whole transaction.
+func (p *Parser) buildFees(swapDatas []SwapData, scope int) Fees {
+	fees := Fees{NetworkFee: p.txMeta.Fee}
+	p.decodeComputeBudget(&fees)
+	fees.JitoTip = p.jitoTips()
+
+	for _, sd := range swapDatas {
+		if v, ok := sd.Data.(*OKXSwapEventData); ok && v.CommissionAmount > 0 {
+			// OKX charges its commission on the source token.
+			fees.Commissions = append(fees.Commissions, Commission{
+				Source: PROTOCOL_OKX,
+				Mint:   v.InputMint,
+				Amount: v.CommissionAmount,
+			})
+			break
+		}
+	}
+	fees.Commissions = append(fees.Commissions, pumpfunFees(swapDatas)...)
+	fees.Commissions = append(fees.Commissions, pumpSwapFees(swapDatas)...)
+
+	for i, outer := range p.txInfo.Message.Instructions {
+		if scope >= 0 && i != scope {
+			continue
+		}
+		progID := p.allAccountKeys[outer.ProgramIDIndex]
+		switch {
+		case progID.Equals(JUPITER_PROGRAM_ID):
+			fees.Commissions = append(fees.Commissions, p.jupiterFeeEvents(i)...)
+		default:
+			if source, ok := botCommissionSources[progID]; ok {
+				fees.Commissions = append(fees.Commissions, p.botFeeTransfers(i, source)...)
+			}
+		}
+	}
+	return fees
+}
+
+// decodeComputeBudget reads SetComputeUnitLimit/SetComputeUnitPrice. Without an
+// explicit limit the runtime grants 200k units per non-ComputeBudget instruction.
+func (p *Parser) decodeComputeBudget(fees *Fees) {
+	var limit uint32
+	var hasLimit bool
+	others := 0
+	for _, inst := range p.txInfo.Message.Instructions {
+		if !p.allAccountKeys[inst.ProgramIDIndex].Equals(COMPUTE_BUDGET_PROGRAM_ID) {
+			others++
+			continue
+		}
+		data := inst.Data
+		switch {
+		case len(data) >= 5 && data[0] == computeBudgetSetLimit:
+			limit, hasLimit = binary.LittleEndian.Uint32(data[1:5]), true
+		case len(data) >= 9 && data[0] == computeBudgetSetPrice:
+			fees.ComputeUnitPrice = binary.LittleEndian.Uint64(data[1:9])
+		}
+	}
+	if !hasLimit {
+		limit = uint32(others * defaultComputeUnitsPerInstruction)
+	}
+	if limit > maxComputeUnitLimit {
+		limit = maxComputeUnitLimit
+	}
+	fees.ComputeUnitLimit = limit
+
+	// micro-lamports → lamports, rounded up like the runtime does; the product
+	// is taken on 128 bits and a fee beyond u64 saturates
+	hi, lo := bits.Mul64(fees.ComputeUnitPrice, uint64(limit))
+	lo, carry := bits.Add64(lo, 999_999, 0)
+	hi += carry
+	if hi >= 1_000_000 {
+		fees.PriorityFee = math.MaxUint64
+	} else {
+		fees.PriorityFee, _ = bits.Div64(hi, lo, 1_000_000)
+	}
+}
+
+// jitoTips sums the SOL sent to Jito tip accounts anywhere in the transaction.
+func (p *Parser) jitoTips() uint64 {
+	var total uint64
+	add := func(inst solana.CompiledInstruction) {
+		if to, lamports, ok := p.systemTransfer(inst); ok && containsKey(JITO_TIP_ACCOUNTS, to) {
+			total += lamports
+		}
+	}
+	for _, inst := range p.txInfo.Message.Instructions {
+		add(inst)
+	}
+	for _, set := range p.txMeta.InnerInstructions {
+		for _, ri := range set.Instructions {
+			add(p.convertRPCToSolanaInstruction(ri))
+		}
+	}
+	return total
+}
+
+// systemTransfer decodes a System Program Transfer, returning recipient and lamports.
+func (p *Parser) systemTransfer(inst solana.CompiledInstruction) (solana.PublicKey, uint64, bool) {
+	if !p.allAccountKeys[inst.ProgramIDIndex].Equals(solana.SystemProgramID) {
+		return solana.PublicKey{}, 0, false
+	}
+	if len(inst.Data) < 12 || binary.LittleEndian.Uint32(inst.Data[:4]) != 2 || len(inst.Accounts) < 2 {
+		return solana.PublicKey{}, 0, false
+	}
+	return p.instructionAccount(inst, 1), binary.LittleEndian.Uint64(inst.Data[4:12]), true
+}
+
+// jupiterFeeEvents decodes the platform-fee events emitted under a Jupiter instruction.
+func (p *Parser) jupiterFeeEvents(index int) []Commission {
+	var out []Commission
+	for _, inst := range p.getInnerInstructions(index) {
+		if !p.allAccountKeys[inst.ProgramIDIndex].Equals(JUPITER_PROGRAM_ID) {
+			continue
+		}
+		data := inst.Data
+		if len(data) < 16+72 || !bytes.Equal(data[:16], JupiterFeeEventDiscriminator[:]) {
+			continue
+		}
+		ev := data[16:]
+		out = append(out, Commission{
+			Source:    PROTOCOL_JUPITER,
+			Recipient: solana.PublicKeyFromBytes(ev[0:32]),
+			Mint:      solana.PublicKeyFromBytes(ev[32:64]),
+			Amount:    binary.LittleEndian.Uint64(ev[64:72]),
+		})
+	}
+	return out
+}
+
+// botFeeTransfers picks the SOL transfers a bot program makes itself (direct
+// CPIs only, so the venue's own transfers deeper in the stack are ignored).
+// Tips, transfers back to a signer and SOL wrapping into the trader's own
+// token accounts are not commissions. Without stack heights (older nodes) the
+// depth is unknown and every system transfer of the set is considered.
+func (p *Parser) botFeeTransfers(index int, source string) []Commission {
+	var out []Commission
+	nested := p.hasStackHeights(index)
+	for _, set := range p.txMeta.InnerInstructions {
+		if set.Index != uint16(index) {
+			continue
+		}
+		for _, ri := range set.Instructions {
+			if nested && ri.StackHeight != 2 {
+				continue
+			}
+			to, lamports, ok := p.systemTransfer(p.convertRPCToSolanaInstruction(ri))
+			if !ok || lamports == 0 || containsKey(JITO_TIP_ACCOUNTS, to) || p.isSigner(to) {
+				continue
+			}
+			if owner, ok := p.tokenAccountOwner(to.String()); ok && p.isSigner(owner) {
+				continue
+			}
+			out = append(out, Commission{
+				Source:    source,
+				Recipient: to,
+				Mint:      NATIVE_SOL_MINT_PROGRAM_ID,
+				Amount:    lamports,
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestBuildFees_ComputeBudgetAndJitoTip(t *testing.T) {
+	limit := make([]byte, 5)
+	limit[0] = computeBudgetSetLimit
+	binary.LittleEndian.PutUint32(limit[1:], 100_000)
+	price := make([]byte, 9)
+	price[0] = computeBudgetSetPrice
+	binary.LittleEndian.PutUint64(price[1:], 250_001) // micro-lamports
+	tip := make([]byte, 12)
+	binary.LittleEndian.PutUint32(tip[:4], 2)
+	binary.LittleEndian.PutUint64(tip[4:], 1_000_000)
+
+	p := newTestParser(t,
+		[]solana.PublicKey{testPayer, JITO_TIP_ACCOUNTS[0], COMPUTE_BUDGET_PROGRAM_ID, solana.SystemProgramID},
+		[]solana.CompiledInstruction{
+			{ProgramIDIndex: 2, Data: limit},
+			{ProgramIDIndex: 2, Data: price},
+			{ProgramIDIndex: 3, Accounts: []uint16{0, 1}, Data: tip},
+		},
+		&rpc.TransactionMeta{Fee: 30_001},
+	)
+
+	fees := p.buildFees(nil, -1)
+	if fees.NetworkFee != 30_001 {
+		t.Errorf("NetworkFee = %d, want 30001", fees.NetworkFee)
+	}
+	if fees.ComputeUnitLimit != 100_000 || fees.ComputeUnitPrice != 250_001 {
+		t.Errorf("compute budget = %d @ %d", fees.ComputeUnitLimit, fees.ComputeUnitPrice)
+	}
+	if fees.PriorityFee != 25_001 { // 25000.1 rounded up
+		t.Errorf("PriorityFee = %d, want 25001", fees.PriorityFee)
+	}
+	if fees.JitoTip != 1_000_000 {
+		t.Errorf("JitoTip = %d, want 1000000", fees.JitoTip)
+	}
+	if len(fees.Commissions) != 0 {
+		t.Errorf("unexpected commissions: %+v", fees.Commissions)
+	}
+}
+
+func TestBuildFees_PriorityFeeOverflow(t *testing.T) {
+	limit := binary.LittleEndian.AppendUint32([]byte{computeBudgetSetLimit}, 1_000_000)
+	price := binary.LittleEndian.AppendUint64([]byte{computeBudgetSetPrice}, 1<<62)
+	p := newTestParser(t, []solana.PublicKey{testPayer, COMPUTE_BUDGET_PROGRAM_ID},
+		[]solana.CompiledInstruction{{ProgramIDIndex: 1, Data: limit}, {ProgramIDIndex: 1, Data: price}},
+		&rpc.TransactionMeta{},
+	)
+	if fees := p.buildFees(nil, -1); fees.PriorityFee != 1<<62 {
+		t.Errorf("PriorityFee = %d, want %d", fees.PriorityFee, uint64(1<<62))
+	}
+}
+
+func TestBotFeeTransfers_NoStackHeights(t *testing.T) {
+	recipient := testKey(1)
+	fee := binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint32(nil, 2), 10_000)
+	p := newTestParser(t,
+		[]solana.PublicKey{testPayer, recipient, solana.SystemProgramID, BANANA_GUN_PROGRAM_ID},
+		[]solana.CompiledInstruction{{ProgramIDIndex: 3, Accounts: []uint16{0}}},
+		&rpc.TransactionMeta{InnerInstructions: []rpc.InnerInstruction{{Index: 0, Instructions: []rpc.CompiledInstruction{
+			{ProgramIDIndex: 2, Accounts: []uint16{0, 1}, Data: fee},
+		}}}},
+	)
+	got := p.botFeeTransfers(0, "bananagun")
+	if len(got) != 1 || !got[0].Recipient.Equals(recipient) || got[0].Amount != 10_000 {
+		t.Errorf("commissions = %+v", got)
+	}
+}
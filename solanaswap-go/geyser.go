@@ -0,0 +1,500 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// The Geyser constructors take the protobuf encoding of the Yellowstone gRPC
+// messages (geyser.proto / solana-storage.proto) and decode the wire format
+// directly, so the yellowstone-grpc Go module is not a dependency. With the
+// generated types, pass proto.Marshal(update) or proto.Marshal(info.Transaction)
+// and proto.Marshal(info.Meta); that re-encodes every update the gRPC client
+// has just decoded. On a hot stream, receive the raw message bytes instead
+// (a pass-through grpc codec on the subscription) so each update is decoded
+// once, here.
+
+// NewTransactionParserFromGeyserUpdate builds a parser from a serialized
+// SubscribeUpdateTransaction, taking the slot from the update. Geyser updates
+// carry no block time, so SwapInfo.Timestamp stays zero unless SetBlockInfo is
+// called with one.
+func NewTransactionParserFromGeyserUpdate(update []byte) (*Parser, error) {
+	var slot uint64
+	var info []byte
+	err := walkProto(update, func(f protoField) error {
+		switch f.num {
+		case 1: // transaction
+			info = f.bytes
+		case 2: // slot
+			slot = f.varint
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode geyser update: %w", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("geyser update has no transaction")
+	}
+
+	var tx, meta []byte
+	err = walkProto(info, func(f protoField) error {
+		switch f.num {
+		case 3: // transaction
+			tx = f.bytes
+		case 4: // meta
+			meta = f.bytes
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode geyser transaction info: %w", err)
+	}
+	parser, err := NewTransactionParserFromGeyser(tx, meta)
+	if err != nil {
+		return nil, err
+	}
+	parser.SetBlockInfo(slot, nil)
+	return parser, nil
+}
+
+// NewTransactionParserFromGeyser builds a parser from a serialized Geyser
+// Transaction and TransactionStatusMeta (SubscribeUpdateTransactionInfo's
+// transaction and meta fields).
+func NewTransactionParserFromGeyser(tx, meta []byte) (*Parser, error) {
+	if tx == nil || meta == nil {
+		return nil, fmt.Errorf("geyser transaction and meta are required")
+	}
+	txInfo, err := geyserTransaction(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode geyser transaction: %w", err)
+	}
+	txMeta, err := geyserTransactionMeta(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode geyser transaction meta: %w", err)
+	}
+	return NewTransactionParserFromTransaction(txInfo, txMeta)
+}
+
+func geyserTransaction(b []byte) (*solana.Transaction, error) {
+	tx := &solana.Transaction{}
+	err := walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1: // signatures
+			if len(f.bytes) != 64 {
+				return fmt.Errorf("signature is %d bytes", len(f.bytes))
+			}
+			tx.Signatures = append(tx.Signatures, solana.SignatureFromBytes(f.bytes))
+		case 2: // message
+			return geyserMessage(f.bytes, &tx.Message)
+		}
+		return nil
+	})
+	return tx, err
+}
+
+func geyserMessage(b []byte, msg *solana.Message) error {
+	return walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1: // header
+			return walkProto(f.bytes, func(h protoField) error {
+				switch h.num {
+				case 1:
+					msg.Header.NumRequiredSignatures = uint8(h.varint)
+				case 2:
+					msg.Header.NumReadonlySignedAccounts = uint8(h.varint)
+				case 3:
+					msg.Header.NumReadonlyUnsignedAccounts = uint8(h.varint)
+				}
+				return nil
+			})
+		case 2: // account_keys
+			key, err := geyserPublicKey(f.bytes)
+			if err != nil {
+				return err
+			}
+			msg.AccountKeys = append(msg.AccountKeys, key)
+		case 3: // recent_blockhash
+			msg.RecentBlockhash = solana.HashFromBytes(f.bytes)
+		case 4: // instructions
+			var inst solana.CompiledInstruction
+			err := walkProto(f.bytes, func(i protoField) error {
+				switch i.num {
+				case 1:
+					inst.ProgramIDIndex = uint16(i.varint)
+				case 2:
+					inst.Accounts = accountIndexes(i.bytes)
+				case 3:
+					inst.Data = append([]byte(nil), i.bytes...)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			msg.Instructions = append(msg.Instructions, inst)
+		case 5: // versioned
+			if f.varint != 0 {
+				msg.SetVersion(solana.MessageVersionV0)
+			}
+		case 6: // address_table_lookups
+			var lookup solana.MessageAddressTableLookup
+			err := walkProto(f.bytes, func(l protoField) error {
+				switch l.num {
+				case 1:
+					key, err := geyserPublicKey(l.bytes)
+					lookup.AccountKey = key
+					return err
+				case 2:
+					lookup.WritableIndexes = append([]byte(nil), l.bytes...)
+				case 3:
+					lookup.ReadonlyIndexes = append([]byte(nil), l.bytes...)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			msg.AddressTableLookups = append(msg.AddressTableLookups, lookup)
+		}
+		return nil
+	})
+}
+
+func geyserTransactionMeta(b []byte) (*rpc.TransactionMeta, error) {
+	meta := &rpc.TransactionMeta{}
+	err := walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1: // err; present only on failure, even if its bytes are empty
+			meta.Err = transactionErrorFromBincode(nil)
+			return walkProto(f.bytes, func(e protoField) error {
+				if e.num == 1 {
+					meta.Err = transactionErrorFromBincode(e.bytes)
+				}
+				return nil
+			})
+		case 2: // fee
+			meta.Fee = f.varint
+		case 3: // pre_balances
+			return appendVarints(f, &meta.PreBalances)
+		case 4: // post_balances
+			return appendVarints(f, &meta.PostBalances)
+		case 5: // inner_instructions
+			set, err := geyserInnerInstructions(f.bytes)
+			if err != nil {
+				return err
+			}
+			meta.InnerInstructions = append(meta.InnerInstructions, set)
+		case 6: // log_messages
+			meta.LogMessages = append(meta.LogMessages, string(f.bytes))
+		case 7: // pre_token_balances
+			tb, err := geyserTokenBalance(f.bytes)
+			if err != nil {
+				return err
+			}
+			meta.PreTokenBalances = append(meta.PreTokenBalances, tb)
+		case 8: // post_token_balances
+			tb, err := geyserTokenBalance(f.bytes)
+			if err != nil {
+				return err
+			}
+			meta.PostTokenBalances = append(meta.PostTokenBalances, tb)
+		case 12: // loaded_writable_addresses
+			key, err := geyserPublicKey(f.bytes)
+			if err != nil {
+				return err
+			}
+			meta.LoadedAddresses.Writable = append(meta.LoadedAddresses.Writable, key)
+		case 13: // loaded_readonly_addresses
+			key, err := geyserPublicKey(f.bytes)
+			if err != nil {
+				return err
+			}
+			meta.LoadedAddresses.ReadOnly = append(meta.LoadedAddresses.ReadOnly, key)
+		case 14: // return_data
+			return walkProto(f.bytes, func(r protoField) error {
+				switch r.num {
+				case 1:
+					key, err := geyserPublicKey(r.bytes)
+					meta.ReturnData.ProgramId = key
+					return err
+				case 2:
+					meta.ReturnData.Data = solana.Data{Content: append([]byte(nil), r.bytes...), Encoding: solana.EncodingBase64}
+				}
+				return nil
+			})
+		case 16: // compute_units_consumed
+			cu := f.varint
+			meta.ComputeUnitsConsumed = &cu
+		}
+		return nil
+	})
+	return meta, err
+}
+
+func geyserInnerInstructions(b []byte) (rpc.InnerInstruction, error) {
+	var set rpc.InnerInstruction
+	err := walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1: // index
+			set.Index = uint16(f.varint)
+		case 2: // instructions
+			var inst rpc.CompiledInstruction
+			err := walkProto(f.bytes, func(i protoField) error {
+				switch i.num {
+				case 1:
+					inst.ProgramIDIndex = uint16(i.varint)
+				case 2:
+					inst.Accounts = accountIndexes(i.bytes)
+				case 3:
+					inst.Data = append([]byte(nil), i.bytes...)
+				case 4: // stack_height, absent before it was recorded
+					inst.StackHeight = uint16(i.varint)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			set.Instructions = append(set.Instructions, inst)
+		}
+		return nil
+	})
+	return set, err
+}
+
+func geyserTokenBalance(b []byte) (rpc.TokenBalance, error) {
+	var tb rpc.TokenBalance
+	err := walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1: // account_index
+			tb.AccountIndex = uint16(f.varint)
+		case 2: // mint
+			mint, err := solana.PublicKeyFromBase58(string(f.bytes))
+			if err != nil {
+				return fmt.Errorf("invalid token balance mint: %w", err)
+			}
+			tb.Mint = mint
+		case 3: // ui_token_amount
+			tb.UiTokenAmount = &rpc.UiTokenAmount{}
+			return walkProto(f.bytes, func(a protoField) error {
+				switch a.num {
+				case 1:
+					v := math.Float64frombits(a.varint)
+					tb.UiTokenAmount.UiAmount = &v
+				case 2:
+					tb.UiTokenAmount.Decimals = uint8(a.varint)
+				case 3:
+					tb.UiTokenAmount.Amount = string(a.bytes)
+				case 4:
+					tb.UiTokenAmount.UiAmountString = string(a.bytes)
+				}
+				return nil
+			})
+		case 4, 5: // owner, program_id; empty when the node didn't report them
+			if len(f.bytes) == 0 {
+				return nil
+			}
+			key, err := solana.PublicKeyFromBase58(string(f.bytes))
+			if err != nil {
+				return fmt.Errorf("invalid token balance owner/program: %w", err)
+			}
+			if f.num == 4 {
+				tb.Owner = &key
+			} else {
+				tb.ProgramId = &key
+			}
+		}
+		return nil
+	})
+	return tb, err
+}
+
+func geyserPublicKey(b []byte) (solana.PublicKey, error) {
+	if len(b) != 32 {
+		return solana.PublicKey{}, fmt.Errorf("public key is %d bytes", len(b))
+	}
+	return solana.PublicKeyFromBytes(b), nil
+}
+
+func accountIndexes(b []byte) []uint16 {
+	out := make([]uint16, len(b))
+	for i, v := range b {
+		out[i] = uint16(v)
+	}
+	return out
+}
+
+// appendVarints decodes a repeated uint64, packed or not.
+func appendVarints(f protoField, out *[]uint64) error {
+	if f.wireType == protoVarint {
+		*out = append(*out, f.varint)
+		return nil
+	}
+	b := f.bytes
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("malformed packed varint")
+		}
+		*out = append(*out, v)
+		b = b[n:]
+	}
+	return nil
+}
+
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoField is one decoded protobuf field: varint holds varint and fixed
+// values, bytes holds length-delimited ones (strings, bytes, messages and
+// packed repeated fields).
+type protoField struct {
+	num      int
+	wireType int
+	varint   uint64
+	bytes    []byte
+}
+
+// walkProto calls fn for each field of the protobuf message b in wire order.
+func walkProto(b []byte, fn func(protoField) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("malformed field tag")
+		}
+		b = b[n:]
+		f := protoField{num: int(tag >> 3), wireType: int(tag & 7)}
+		switch f.wireType {
+		case protoVarint:
+			f.varint, n = binary.Uvarint(b)
+			if n <= 0 {
+				return fmt.Errorf("malformed varint in field %d", f.num)
+			}
+			b = b[n:]
+		case protoFixed64:
+			if len(b) < 8 {
+				return fmt.Errorf("truncated field %d", f.num)
+			}
+			f.varint = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return fmt.Errorf("truncated field %d", f.num)
+			}
+			f.bytes = b[n : n+int(l)]
+			b = b[n+int(l):]
+		case protoFixed32:
+			if len(b) < 4 {
+				return fmt.Errorf("truncated field %d", f.num)
+			}
+			f.varint = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", f.wireType, f.num)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transactionErrorVariants and instructionErrorVariants are the bincode enum
+// orders of TransactionError and InstructionError in the Solana SDK.
+var transactionErrorVariants = []string{
+	"AccountInUse", "AccountLoadedTwice", "AccountNotFound", "ProgramAccountNotFound",
+	"InsufficientFundsForFee", "InvalidAccountForFee", "AlreadyProcessed", "BlockhashNotFound",
+	"InstructionError", "CallChainTooDeep", "MissingSignatureForFee", "InvalidAccountIndex",
+	"SignatureFailure", "InvalidProgramForExecution", "SanitizeFailure", "ClusterMaintenance",
+	"AccountBorrowOutstanding", "WouldExceedMaxBlockCostLimit", "UnsupportedVersion",
+	"InvalidWritableAccount", "WouldExceedMaxAccountCostLimit", "WouldExceedAccountDataBlockLimit",
+	"TooManyAccountLocks", "AddressLookupTableNotFound", "InvalidAddressLookupTableOwner",
+	"InvalidAddressLookupTableData", "InvalidAddressLookupTableIndex", "InvalidRentPayingAccount",
+	"WouldExceedMaxVoteCostLimit", "WouldExceedAccountDataTotalLimit", "DuplicateInstruction",
+	"InsufficientFundsForRent", "MaxLoadedAccountsDataSizeExceeded", "InvalidLoadedAccountsDataSizeLimit",
+	"ResanitizationNeeded", "ProgramExecutionTemporarilyRestricted", "UnbalancedTransaction",
+	"ProgramCacheHitMaxLimit", "CommitCancelled",
+}
+
+var instructionErrorVariants = []string{
+	"GenericError", "InvalidArgument", "InvalidInstructionData", "InvalidAccountData",
+	"AccountDataTooSmall", "InsufficientFunds", "IncorrectProgramId", "MissingRequiredSignature",
+	"AccountAlreadyInitialized", "UninitializedAccount", "UnbalancedInstruction", "ModifiedProgramId",
+	"ExternalAccountLamportSpend", "ExternalAccountDataModified", "ReadonlyLamportChange",
+	"ReadonlyDataModified", "DuplicateAccountIndex", "ExecutableModified", "RentEpochModified",
+	"NotEnoughAccountKeys", "AccountDataSizeChanged", "AccountNotExecutable", "AccountBorrowFailed",
+	"AccountBorrowOutstanding", "DuplicateAccountOutOfSync", "Custom", "InvalidError",
+	"ExecutableDataModified", "ExecutableLamportChange", "ExecutableAccountNotRentExempt",
+	"UnsupportedProgramId", "CallDepth", "MissingAccount", "ReentrancyNotAllowed",
+	"MaxSeedLengthExceeded", "InvalidSeeds", "InvalidRealloc", "ComputationalBudgetExceeded",
+	"PrivilegeEscalation", "ProgramEnvironmentSetupFailure", "ProgramFailedToComplete",
+	"ProgramFailedToCompile", "Immutable", "IncorrectAuthority", "BorshIoError",
+	"AccountNotRentExempt", "InvalidAccountOwner", "ArithmeticOverflow", "UnsupportedSysvar",
+	"IllegalOwner", "MaxAccountsDataAllocationsExceeded", "MaxAccountsResizeExceeded",
+	"MaxInstructionTraceLengthExceeded", "BuiltinProgramsMustConsumeComputeUnits",
+}
+
+// transactionErrorFromBincode converts Geyser's bincode-encoded TransactionError
+// to the shape JSON-RPC reports in meta.err ("AccountInUse",
+// {"InstructionError":[2,{"Custom":6001}]}, ...), which IsFailed and
+// ParseFailedSwap read. The variant tables must follow the SDK; bincode enums
+// only grow at the end, so a variant added since comes back as its raw tag
+// ({"UnknownTransactionError":33}, or {"UnknownInstructionError":54} inside an
+// InstructionError, keeping the failing instruction) rather than a wrong name.
+func transactionErrorFromBincode(b []byte) interface{} {
+	if len(b) < 4 {
+		return map[string]interface{}{"UnknownTransactionError": fmt.Sprintf("%x", b)}
+	}
+	tag := binary.LittleEndian.Uint32(b)
+	if int(tag) >= len(transactionErrorVariants) {
+		return map[string]interface{}{"UnknownTransactionError": float64(tag)}
+	}
+	name := transactionErrorVariants[tag]
+	rest := b[4:]
+	switch name {
+	case "InstructionError":
+		if len(rest) < 5 {
+			return name
+		}
+		index := float64(rest[0])
+		ieTag := binary.LittleEndian.Uint32(rest[1:])
+		detail := rest[5:]
+		if int(ieTag) >= len(instructionErrorVariants) {
+			return map[string]interface{}{name: []interface{}{index, map[string]interface{}{"UnknownInstructionError": float64(ieTag)}}}
+		}
+		var ie interface{} = instructionErrorVariants[ieTag]
+		switch instructionErrorVariants[ieTag] {
+		case "Custom":
+			if len(detail) >= 4 {
+				ie = map[string]interface{}{"Custom": float64(binary.LittleEndian.Uint32(detail))}
+			}
+		case "BorshIoError":
+			if len(detail) >= 8 {
+				l := binary.LittleEndian.Uint64(detail)
+				if l <= uint64(len(detail)-8) {
+					ie = map[string]interface{}{"BorshIoError": string(detail[8 : 8+l])}
+				}
+			}
+		}
+		return map[string]interface{}{name: []interface{}{index, ie}}
+	case "DuplicateInstruction":
+		if len(rest) >= 1 {
+			return map[string]interface{}{name: float64(rest[0])}
+		}
+	case "InsufficientFundsForRent", "ProgramExecutionTemporarilyRestricted":
+		if len(rest) >= 1 {
+			return map[string]interface{}{name: map[string]interface{}{"account_index": float64(rest[0])}}
+		}
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func loadGeyserFixture(t *testing.T, name string) *Parser {
+	t.Helper()
+	update, err := os.ReadFile(filepath.Join("testdata", "geyser", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewTransactionParserFromGeyserUpdate(update)
+	if err != nil {
+		t.Fatalf("NewTransactionParserFromGeyserUpdate: %v", err)
+	}
+	return p
+}
+
+// router_swap.pb is the two-hop bot route of newTwoHopRouterParser as a v0
+// SubscribeUpdateTransaction, with the Token, Raydium V4 and Orca programs
+// loaded read-only from a lookup table and a u64 set as return data.
+func TestGeyser_RouterSwap(t *testing.T) {
+	p := loadGeyserFixture(t, "router_swap.pb")
+
+	if p.slot != 312_345_678 {
+		t.Errorf("slot = %d", p.slot)
+	}
+	if len(p.allAccountKeys) != 12 || !p.allAccountKeys[10].Equals(RAYDIUM_V4_PROGRAM_ID) {
+		t.Fatalf("account keys = %v", p.allAccountKeys)
+	}
+	if n := len(p.txInfo.Message.AddressTableLookups); n != 1 {
+		t.Errorf("lookups = %d", n)
+	}
+	if len(p.txMeta.PostTokenBalances) != 6 || len(p.txMeta.LogMessages) != 2 || p.txMeta.Fee != 5000 {
+		t.Errorf("meta = %+v", p.txMeta)
+	}
+	if cu := p.txMeta.ComputeUnitsConsumed; cu == nil || *cu != 84_210 {
+		t.Errorf("compute units = %v", cu)
+	}
+	if p.txMeta.PostBalances[0] != 999_995_000 {
+		t.Errorf("post balances = %v", p.txMeta.PostBalances)
+	}
+	if rd := p.txMeta.ReturnData; !rd.ProgramId.Equals(RAYDIUM_V4_PROGRAM_ID) || binary.LittleEndian.Uint64(rd.Data.Content) != 42_000 {
+		t.Errorf("return data = %+v", rd)
+	}
+	if !p.hasStackHeights(0) {
+		t.Fatal("stack heights were dropped")
+	}
+
+	legs := p.processRouterSwaps(0)
+	want := []SwapType{RAYDIUM, RAYDIUM, ORCA, ORCA}
+	if len(legs) != len(want) {
+		t.Fatalf("got %d legs, want %d", len(legs), len(want))
+	}
+	for i, leg := range legs {
+		if leg.Type != want[i] {
+			t.Errorf("leg %d type = %s, want %s", i, leg.Type, want[i])
+		}
+	}
+	if d := p.splDecimalsMap[testBonkMint]; d != 5 {
+		t.Errorf("bonk decimals = %d", d)
+	}
+}
+
+func TestGeyser_FailedSlippage(t *testing.T) {
+	p := loadGeyserFixture(t, "failed_slippage.pb")
+	if !p.IsFailed() {
+		t.Fatal("IsFailed = false")
+	}
+	index, code := p.instructionError()
+	if index != 1 || code == nil || *code != 6001 {
+		t.Fatalf("instructionError = %d, %v", index, code)
+	}
+	if prog, reason := p.classifyFailure(code); !prog.Equals(JUPITER_PROGRAM_ID) || reason != FailureSlippage {
+		t.Errorf("classifyFailure = %s, %s", prog, reason)
+	}
+}
+
+func TestTransactionErrorFromBincode(t *testing.T) {
+	for _, tc := range []struct {
+		in   []byte
+		want string
+	}{
+		{[]byte{0, 0, 0, 0}, `AccountInUse`},
+		{[]byte{8, 0, 0, 0, 3, 2, 0, 0, 0}, `{"InstructionError":[3,"InvalidInstructionData"]}`},
+		{[]byte{31, 0, 0, 0, 4}, `{"InsufficientFundsForRent":{"account_index":4}}`},
+		{[]byte{200, 0, 0, 0}, `{"UnknownTransactionError":200}`},
+		{[]byte{8, 0, 0, 0, 1, 99, 0, 0, 0}, `{"InstructionError":[1,{"UnknownInstructionError":99}]}`},
+		{nil, `{"UnknownTransactionError":""}`},
+	} {
+		if got := errorString(transactionErrorFromBincode(tc.in)); got != tc.want {
+			t.Errorf("%v: got %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestGeyser_EmptyErrorIsFailed(t *testing.T) {
+	// TransactionStatusMeta{err: TransactionError{}}: the error bytes are omitted
+	meta, err := geyserTransactionMeta([]byte{0x0a, 0x00})
+	if err != nil {
+		t.Fatalf("geyserTransactionMeta: %v", err)
+	}
+	if meta.Err == nil {
+		t.Error("meta.Err = nil for a meta carrying an error")
+	}
+}
+
+func TestGeyser_Malformed(t *testing.T) {
+	if _, err := NewTransactionParserFromGeyser([]byte{0x12, 0x05, 1}, []byte{}); err == nil {
+		t.Error("truncated message accepted")
+	}
+	short := append([]byte{0x12, 0x04, 0x12, 0x02}, solana.PublicKey{}.Bytes()[:2]...)
+	if _, err := NewTransactionParserFromGeyser(short, []byte{}); err == nil {
+		t.Error("2-byte account key accepted")
+	}
+}
@@ -0,0 +1,276 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+
+	"github.com/P-HOW/solana-swap-decode/solanaswap-go/idl"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Hop is one leg of a route: the amounts that went through a single pool.
+type Hop struct {
+	ProgramID solana.PublicKey
+	Pool      solana.PublicKey
+
+	InputMint   solana.PublicKey
+	InputAmount uint64
+
+	OutputMint   solana.PublicKey
+	OutputAmount uint64
+
+	// InputReserve and OutputReserve are the pool's reserves of each mint before
+	// the swap, for venues whose events report them (PumpSwap); zero otherwise.
+	InputReserve  uint64
+	OutputReserve uint64
+}
+
+// Anchor emit_cpi! prefix: every self-invoked event instruction starts with it.
+var anchorEventCPIPrefix = idl.EventCPIPrefix
+
+// isAnchorEventCPI reports whether data is an Anchor emit_cpi! self-invocation.
+func isAnchorEventCPI(data []byte) bool {
+	return len(data) >= 8 && bytes.Equal(data[:8], anchorEventCPIPrefix)
+}
+
+var orcaSwapV2Disc = anchorDiscriminator8("swap_v2")
+
+// poolAccountFor returns the pool/market account an AMM instruction operates on.
+// Indices follow each program's swap instruction account layout.
+func (p *Parser) poolAccountFor(inst solana.CompiledInstruction) (solana.PublicKey, bool) {
+	progID := p.allAccountKeys[inst.ProgramIDIndex]
+	idx := -1
+	switch {
+	case progID.Equals(RAYDIUM_V4_PROGRAM_ID):
+		idx = 1 // amm
+	case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID):
+		idx = 3 // pool_state
+	case progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
+		idx = 2 // pool_state
+	case progID.Equals(RAYDIUM_LAUNCHLAB_PROGRAM_ID):
+		idx = 4 // pool_state
+	case progID.Equals(ORCA_PROGRAM_ID):
+		idx = 2 // whirlpool
+		if len(inst.Data) >= 8 && bytes.Equal(inst.Data[:8], orcaSwapV2Disc[:]) {
+			idx = 4
+		}
+	case progID.Equals(METEORA_PROGRAM_ID), progID.Equals(METEORA_POOLS_PROGRAM_ID):
+		idx = 0 // lb_pair / pool
+	case progID.Equals(METEORA_DAMM_V2_PROGRAM_ID):
+		idx = 1 // pool
+	case progID.Equals(METEORA_DBC_PROGRAM_ID):
+		idx = 2 // pool
+	case progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
+		idx = 0 // pool
+	case progID.Equals(PUMP_FUN_PROGRAM_ID):
+		idx = 3 // bonding_curve
+	case progID.Equals(MOONSHOT_PROGRAM_ID):
+		idx = 2 // curve_account
+	case progID.Equals(PHOENIX_PROGRAM_ID):
+		idx = 2 // market
+	}
+	if idx < 0 || idx >= len(inst.Accounts) || int(inst.Accounts[idx]) >= len(p.allAccountKeys) {
+		return solana.PublicKey{}, false
+	}
+	return p.allAccountKeys[inst.Accounts[idx]], true
+}
+
+// isHopInvocation reports whether inst is a call into a registered AMM
+// (Anchor event self-CPIs and Phoenix logs are not hops).
+func (p *Parser) isHopInvocation(inst solana.CompiledInstruction) bool {
+	d := p.decoderFor(p.allAccountKeys[inst.ProgramIDIndex])
+	if d == nil || d.Role() != RoleAMM || p.isPhoenixLogInstruction(inst) {
+		return false
+	}
+	return !isAnchorEventCPI(inst.Data)
+}
+
+// ammInvocations lists, in order, the AMM calls made by the outer instruction
+// at index (the outer instruction itself first when it is an AMM).
+func (p *Parser) ammInvocations(index int) []solana.CompiledInstruction {
+	var calls []solana.CompiledInstruction
+	if outer := p.txInfo.Message.Instructions[index]; p.isHopInvocation(outer) {
+		calls = append(calls, outer)
+	}
+	for _, inst := range p.getInnerInstructions(index) {
+		if p.isHopInvocation(inst) {
+			calls = append(calls, inst)
+		}
+	}
+	return calls
+}
+
+// buildHops derives the route from the legs of one swap. Event-based venues
+// carry one event per hop; everything else is rebuilt from the transfers
+// made under each AMM invocation.
+func (p *Parser) buildHops(swapDatas []SwapData) []Hop {
+	var hops []Hop
+	pools := make(map[int]map[solana.PublicKey][]solana.PublicKey)
+	nextPool := func(index int, program solana.PublicKey) solana.PublicKey {
+		if _, ok := pools[index]; !ok {
+			byProgram := make(map[solana.PublicKey][]solana.PublicKey)
+			for _, inst := range p.ammInvocations(index) {
+				if pool, ok := p.poolAccountFor(inst); ok {
+					prog := p.allAccountKeys[inst.ProgramIDIndex]
+					byProgram[prog] = append(byProgram[prog], pool)
+				}
+			}
+			pools[index] = byProgram
+		}
+		queue := pools[index][program]
+		if len(queue) == 0 {
+			return solana.PublicKey{}
+		}
+		pools[index][program] = queue[1:]
+		return queue[0]
+	}
+
+	for _, sd := range swapDatas {
+		switch v := sd.Data.(type) {
+		case *JupiterSwapEventData:
+			hops = append(hops, Hop{
+				ProgramID:    v.Amm,
+				Pool:         nextPool(sd.InstructionIndex, v.Amm),
+				InputMint:    v.InputMint,
+				InputAmount:  v.InputAmount,
+				OutputMint:   v.OutputMint,
+				OutputAmount: v.OutputAmount,
+			})
+		case *PumpfunTradeEvent:
+			hop := Hop{ProgramID: PUMP_FUN_PROGRAM_ID, Pool: nextPool(sd.InstructionIndex, PUMP_FUN_PROGRAM_ID)}
+			if v.IsBuy {
+				hop.InputMint, hop.InputAmount = NATIVE_SOL_MINT_PROGRAM_ID, v.SolAmount
+				hop.OutputMint, hop.OutputAmount = v.Mint, v.TokenAmount
+			} else {
+				hop.InputMint, hop.InputAmount = v.Mint, v.TokenAmount
+				hop.OutputMint, hop.OutputAmount = NATIVE_SOL_MINT_PROGRAM_ID, v.SolAmount
+			}
+			hops = append(hops, hop)
+		case *PumpSwapTradeEvent:
+			hop := Hop{ProgramID: PUMPFUN_AMM_PROGRAM_ID, Pool: v.Pool}
+			hop.InputMint, hop.InputAmount, hop.OutputMint, hop.OutputAmount = v.sides()
+			if v.IsBuy {
+				hop.InputReserve, hop.OutputReserve = v.PoolQuoteReserves, v.PoolBaseReserves
+			} else {
+				hop.InputReserve, hop.OutputReserve = v.PoolBaseReserves, v.PoolQuoteReserves
+			}
+			hops = append(hops, hop)
+		case *MoonshotTradeInstructionWithMint:
+			hop := Hop{ProgramID: MOONSHOT_PROGRAM_ID, Pool: nextPool(sd.InstructionIndex, MOONSHOT_PROGRAM_ID)}
+			if v.TradeType == TradeTypeBuy {
+				hop.InputMint, hop.InputAmount = NATIVE_SOL_MINT_PROGRAM_ID, v.CollateralAmount
+				hop.OutputMint, hop.OutputAmount = v.Mint, v.TokenAmount
+			} else {
+				hop.InputMint, hop.InputAmount = v.Mint, v.TokenAmount
+				hop.OutputMint, hop.OutputAmount = NATIVE_SOL_MINT_PROGRAM_ID, v.CollateralAmount
+			}
+			hops = append(hops, hop)
+		}
+	}
+	if len(hops) > 0 {
+		return hops
+	}
+
+	indexes := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, sd := range swapDatas {
+		if !seen[sd.InstructionIndex] {
+			seen[sd.InstructionIndex] = true
+			indexes = append(indexes, sd.InstructionIndex)
+		}
+	}
+	sort.Ints(indexes)
+	for _, idx := range indexes {
+		hops = append(hops, p.transferHops(idx)...)
+	}
+	return hops
+}
+
+type hopTransfer struct {
+	mint        string
+	amount      uint64
+	authority   string
+	destination string
+}
+
+// transferHops walks the CPIs under the outer instruction at index and turns the
+// transfers following each AMM invocation into a hop. Legs signed by the trader
+// (a signer, or the owner the previous hop paid into) go into the pool; legs
+// signed by anyone else, the pool's vault authority, come out of it.
+func (p *Parser) transferHops(index int) []Hop {
+	var hops []Hop
+	var cur *Hop
+	var transfers []hopTransfer
+	trader := p.resolveTrader().String()
+	carried := map[string]bool{}
+
+	fromTrader := func(authority string) bool {
+		if authority == trader || carried[authority] {
+			return true
+		}
+		pk, err := solana.PublicKeyFromBase58(authority)
+		return err == nil && p.isSigner(pk)
+	}
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		var in, out *hopTransfer
+		for k := range transfers {
+			t := &transfers[k]
+			switch {
+			case fromTrader(t.authority):
+				if in == nil {
+					in = t
+				}
+			case out == nil:
+				out = t
+			}
+		}
+		if in == nil || out == nil || in.mint == out.mint {
+			return
+		}
+		inMint, err1 := solana.PublicKeyFromBase58(in.mint)
+		outMint, err2 := solana.PublicKeyFromBase58(out.mint)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		cur.InputMint, cur.InputAmount = inMint, in.amount
+		cur.OutputMint, cur.OutputAmount = outMint, out.amount
+		hops = append(hops, *cur)
+		carried = map[string]bool{}
+		if owner, ok := p.tokenAccountOwner(out.destination); ok {
+			carried[owner.String()] = true
+		}
+	}
+	start := func(inst solana.CompiledInstruction) {
+		flush()
+		pool, _ := p.poolAccountFor(inst)
+		cur = &Hop{ProgramID: p.allAccountKeys[inst.ProgramIDIndex], Pool: pool}
+		transfers = nil
+	}
+
+	if outer := p.txInfo.Message.Instructions[index]; p.isHopInvocation(outer) {
+		start(outer)
+	}
+	for _, inst := range p.getInnerInstructions(index) {
+		switch {
+		case p.isHopInvocation(inst):
+			start(inst)
+		case cur == nil:
+		case p.isTransferCheck(inst):
+			if tc := p.processTransferCheck(inst); tc != nil {
+				if amt, err := strconv.ParseUint(tc.Info.TokenAmount.Amount, 10, 64); err == nil {
+					transfers = append(transfers, hopTransfer{tc.Info.Mint, amt, tc.Info.Authority, tc.Info.Destination})
+				}
+			}
+		case p.isTransfer(inst):
+			if tr := p.processTransfer(inst); tr != nil {
+				transfers = append(transfers, hopTransfer{tr.Mint, tr.Info.Amount, tr.Info.Authority, tr.Info.Destination})
+			}
+		}
+	}
+	flush()
+	return hops
+}
@@ -0,0 +1,128 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func testKey(b byte) solana.PublicKey {
+	return solana.PublicKeyFromBytes(bytes.Repeat([]byte{b}, 32))
+}
+
+func transferData(amount uint64) solana.Base58 {
+	data := make([]byte, 9)
+	data[0] = 3
+	binary.LittleEndian.PutUint64(data[1:], amount)
+	return data
+}
+
+func tokenBalance(index uint16, mint string, owner solana.PublicKey, amount string, decimals uint8) rpc.TokenBalance {
+	o := owner
+	return rpc.TokenBalance{
+		AccountIndex:  index,
+		Owner:         &o,
+		Mint:          solana.MustPublicKeyFromBase58(mint),
+		UiTokenAmount: &rpc.UiTokenAmount{Amount: amount, Decimals: decimals},
+	}
+}
+
+// newRaydiumV4Parser builds a direct Raydium V4 SOL→BONK swap by testPayer.
+func newRaydiumV4Parser(t *testing.T) (*Parser, solana.PublicKey) {
+	t.Helper()
+	userSOL, userBonk, vaultSOL, vaultBonk, amm, ammAuthority := testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), testKey(6)
+	keys := []solana.PublicKey{
+		testPayer,             // 0
+		userSOL,               // 1
+		userBonk,              // 2
+		vaultSOL,              // 3
+		vaultBonk,             // 4
+		amm,                   // 5
+		ammAuthority,          // 6
+		solana.TokenProgramID, // 7
+		RAYDIUM_V4_PROGRAM_ID, // 8
+	}
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 7, Accounts: []uint16{1, 3, 0}, Data: transferData(1_000_000)},
+				{ProgramIDIndex: 7, Accounts: []uint16{4, 2, 6}, Data: transferData(42_000)},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(1, sol, testPayer, "0", 9),
+			tokenBalance(2, testBonkMint, testPayer, "42000", 5),
+			tokenBalance(3, sol, ammAuthority, "1000000", 9),
+			tokenBalance(4, testBonkMint, ammAuthority, "0", 5),
+		},
+	}
+	p := newTestParser(t, keys,
+		[]solana.CompiledInstruction{{ProgramIDIndex: 8, Accounts: []uint16{7, 5, 6, 3, 4, 1, 2, 0}, Data: []byte{9}}},
+		meta,
+	)
+	return p, amm
+}
+
+func TestBuildHops_RaydiumV4Transfers(t *testing.T) {
+	p, amm := newRaydiumV4Parser(t)
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	info, err := p.ProcessSwapData(swaps)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if len(info.Hops) != 1 {
+		t.Fatalf("got %d hops, want 1: %+v", len(info.Hops), info.Hops)
+	}
+	hop := info.Hops[0]
+	if !hop.ProgramID.Equals(RAYDIUM_V4_PROGRAM_ID) || !hop.Pool.Equals(amm) {
+		t.Errorf("hop program/pool = %s/%s", hop.ProgramID, hop.Pool)
+	}
+	if hop.InputMint.String() != sol || hop.InputAmount != 1_000_000 {
+		t.Errorf("hop input = %s/%d", hop.InputMint, hop.InputAmount)
+	}
+	if hop.OutputMint.String() != testBonkMint || hop.OutputAmount != 42_000 {
+		t.Errorf("hop output = %s/%d", hop.OutputMint, hop.OutputAmount)
+	}
+}
+
+func TestTransferHops_SplitByAuthority(t *testing.T) {
+	p, _ := newRaydiumV4Parser(t)
+	// The pool pays out first, then the trader pays in and tips a fee account
+	// (the pool's own SOL vault stands in for it).
+	p.txMeta.InnerInstructions[0].Instructions = []rpc.CompiledInstruction{
+		{ProgramIDIndex: 7, Accounts: []uint16{4, 2, 6}, Data: transferData(42_000)},
+		{ProgramIDIndex: 7, Accounts: []uint16{1, 3, 0}, Data: transferData(1_000_000)},
+		{ProgramIDIndex: 7, Accounts: []uint16{1, 3, 0}, Data: transferData(2_500)},
+	}
+
+	hops := p.transferHops(0)
+	if len(hops) != 1 {
+		t.Fatalf("got %d hops, want 1: %+v", len(hops), hops)
+	}
+	if h := hops[0]; !h.InputMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || h.InputAmount != 1_000_000 || h.OutputMint.String() != testBonkMint || h.OutputAmount != 42_000 {
+		t.Errorf("hop = %s %d → %s %d", h.InputMint, h.InputAmount, h.OutputMint, h.OutputAmount)
+	}
+}
+
+func TestTransferHops_TwoHopRoute(t *testing.T) {
+	hops := newTwoHopRouterParser(t, true).transferHops(0)
+	if len(hops) != 2 {
+		t.Fatalf("got %d hops, want 2: %+v", len(hops), hops)
+	}
+	if h := hops[0]; !h.ProgramID.Equals(RAYDIUM_V4_PROGRAM_ID) || h.InputAmount != 1_000_000_000 || h.OutputAmount != 150_000_000 {
+		t.Errorf("hop 0 = %+v", h)
+	}
+	if h := hops[1]; !h.ProgramID.Equals(ORCA_PROGRAM_ID) || h.InputAmount != 150_000_000 || h.OutputAmount != 7_000_000 {
+		t.Errorf("hop 1 = %+v", h)
+	}
+}
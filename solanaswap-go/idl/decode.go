@@ -0,0 +1,220 @@
+package idl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// reader decodes Borsh values into generic Go values: fixed-width integers
+// keep their Go type, u128/i128 become *big.Int, pubkey solana.PublicKey,
+// bytes and u8 arrays []byte, vec/array []interface{}, option nil or the
+// value, structs map[string]interface{} (tuple fields keyed "0", "1", ...),
+// and enums the variant name or {variant: fields}.
+type reader struct {
+	data  []byte
+	pos   int
+	types *IDL
+}
+
+func (r *reader) take(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, fmt.Errorf("need %d bytes at offset %d, have %d", n, r.pos, len(r.data)-r.pos)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *reader) fields(fields []Field) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(fields))
+	for i, f := range fields {
+		v, err := r.value(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		name := f.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+func (r *reader) value(t Type) (interface{}, error) {
+	switch t.Kind {
+	case "bool":
+		b, err := r.take(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case "u8":
+		b, err := r.take(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case "i8":
+		b, err := r.take(1)
+		if err != nil {
+			return nil, err
+		}
+		return int8(b[0]), nil
+	case "u16", "i16":
+		b, err := r.take(2)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint16(b)
+		if t.Kind == "i16" {
+			return int16(v), nil
+		}
+		return v, nil
+	case "u32", "i32", "f32":
+		b, err := r.take(4)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint32(b)
+		switch t.Kind {
+		case "i32":
+			return int32(v), nil
+		case "f32":
+			return math.Float32frombits(v), nil
+		}
+		return v, nil
+	case "u64", "i64", "f64":
+		b, err := r.take(8)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint64(b)
+		switch t.Kind {
+		case "i64":
+			return int64(v), nil
+		case "f64":
+			return math.Float64frombits(v), nil
+		}
+		return v, nil
+	case "u128", "i128":
+		b, err := r.take(16)
+		if err != nil {
+			return nil, err
+		}
+		be := make([]byte, 16)
+		for i := range b {
+			be[15-i] = b[i]
+		}
+		v := new(big.Int).SetBytes(be)
+		if t.Kind == "i128" && b[15]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 128))
+		}
+		return v, nil
+	case "pubkey":
+		b, err := r.take(32)
+		if err != nil {
+			return nil, err
+		}
+		return solana.PublicKeyFromBytes(b), nil
+	case "string", "bytes":
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+		b, err := r.take(n)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == "string" {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+	case "vec":
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+		return r.seq(*t.Elem, n)
+	case "array":
+		if t.Elem.Kind == "u8" {
+			b, err := r.take(t.Len)
+			if err != nil {
+				return nil, err
+			}
+			return append([]byte(nil), b...), nil
+		}
+		return r.seq(*t.Elem, t.Len)
+	case "option", "coption":
+		size := 1
+		if t.Kind == "coption" {
+			size = 4
+		}
+		tag, err := r.take(size)
+		if err != nil {
+			return nil, err
+		}
+		if tag[0] == 0 {
+			return nil, nil
+		}
+		return r.value(*t.Elem)
+	case "defined":
+		def := r.types.typeDef(t.Defined)
+		if def == nil {
+			return nil, fmt.Errorf("undefined type %s", t.Defined)
+		}
+		return r.defined(def)
+	}
+	return nil, fmt.Errorf("unsupported type %q", t.Kind)
+}
+
+func (r *reader) length() (int, error) {
+	b, err := r.take(4)
+	if err != nil {
+		return 0, err
+	}
+	n := binary.LittleEndian.Uint32(b)
+	if int(n) > len(r.data)-r.pos {
+		return 0, fmt.Errorf("length %d exceeds remaining %d bytes", n, len(r.data)-r.pos)
+	}
+	return int(n), nil
+}
+
+func (r *reader) seq(elem Type, n int) ([]interface{}, error) {
+	out := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := r.value(elem)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (r *reader) defined(def *TypeDef) (interface{}, error) {
+	if def.Kind != "enum" {
+		return r.fields(def.Fields)
+	}
+	tag, err := r.take(1)
+	if err != nil {
+		return nil, err
+	}
+	if int(tag[0]) >= len(def.Variants) {
+		return nil, fmt.Errorf("%s: variant %d out of range", def.Name, tag[0])
+	}
+	v := def.Variants[tag[0]]
+	if len(v.Fields) == 0 {
+		return v.Name, nil
+	}
+	fields, err := r.fields(v.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{v.Name: fields}, nil
+}
@@ -0,0 +1,370 @@
+// Package idl loads Anchor IDL JSON files (legacy and 0.30+ formats) and
+// decodes instructions and events of the programs they describe.
+package idl
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// IDL is an Anchor program interface.
+type IDL struct {
+	Address      string
+	Name         string
+	Instructions []Instruction
+	Events       []Event
+	Types        []TypeDef
+}
+
+// Instruction is an instruction with its discriminator, flattened account
+// list and arguments.
+type Instruction struct {
+	Name          string
+	Discriminator []byte
+	Accounts      []Account
+	Args          []Field
+}
+
+// Account is one instruction account slot.
+type Account struct {
+	Name     string
+	Writable bool
+	Signer   bool
+}
+
+// Event is an emitted event. Fields are resolved from the types section when
+// the IDL only names the event (0.30+).
+type Event struct {
+	Name          string
+	Discriminator []byte
+	Fields        []Field
+}
+
+// Field is a named (or, in tuple structs and variants, unnamed) value.
+type Field struct {
+	Name string
+	Type Type
+}
+
+// Type is an IDL type: a primitive ("u64", "pubkey", ...) in Kind, or one of
+// "vec", "option", "coption", "array" (Elem, Len) and "defined" (Defined).
+type Type struct {
+	Kind    string
+	Elem    *Type
+	Len     int
+	Defined string
+}
+
+// TypeDef is a named struct or enum from the types section.
+type TypeDef struct {
+	Name     string
+	Kind     string // "struct" or "enum"
+	Fields   []Field
+	Variants []Variant
+}
+
+// Variant is an enum variant; Fields is empty for unit variants.
+type Variant struct {
+	Name   string
+	Fields []Field
+}
+
+// InstructionDiscriminator is the Anchor instruction discriminator,
+// sha256("global:<name>")[:8].
+func InstructionDiscriminator(name string) [8]byte {
+	return discriminator("global:" + name)
+}
+
+// EventDiscriminator is the Anchor event discriminator, sha256("event:<Name>")[:8].
+func EventDiscriminator(name string) [8]byte {
+	return discriminator("event:" + name)
+}
+
+func discriminator(preimage string) [8]byte {
+	sum := sha256.Sum256([]byte(preimage))
+	var out [8]byte
+	copy(out[:], sum[:8])
+	return out
+}
+
+// LoadFile reads and parses an IDL JSON file.
+func LoadFile(path string) (*IDL, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IDL %s: %w", path, err)
+	}
+	idl, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse IDL %s: %w", path, err)
+	}
+	return idl, nil
+}
+
+// LoadDir parses every *.json file in dir.
+func LoadDir(dir string) ([]*IDL, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	idls := make([]*IDL, 0, len(paths))
+	for _, path := range paths {
+		idl, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		idls = append(idls, idl)
+	}
+	return idls, nil
+}
+
+// Raw JSON shapes. Legacy IDLs use camelCase names, isMut/isSigner, inline
+// event fields and metadata.address; 0.30+ IDLs carry explicit
+// discriminators, writable/signer and a top-level address.
+type rawIDL struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Metadata struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+	} `json:"metadata"`
+	Instructions []struct {
+		Name     string            `json:"name"`
+		RawDisc  []int             `json:"discriminator"`
+		Accounts []json.RawMessage `json:"accounts"`
+		Args     []json.RawMessage `json:"args"`
+	} `json:"instructions"`
+	Events []struct {
+		Name    string            `json:"name"`
+		RawDisc []int             `json:"discriminator"`
+		Fields  []json.RawMessage `json:"fields"`
+	} `json:"events"`
+	Types []struct {
+		Name string `json:"name"`
+		Type struct {
+			Kind     string            `json:"kind"`
+			Fields   []json.RawMessage `json:"fields"`
+			Variants []struct {
+				Name   string            `json:"name"`
+				Fields []json.RawMessage `json:"fields"`
+			} `json:"variants"`
+		} `json:"type"`
+	} `json:"types"`
+}
+
+type rawAccount struct {
+	Name     string            `json:"name"`
+	IsMut    bool              `json:"isMut"`
+	IsSigner bool              `json:"isSigner"`
+	Writable bool              `json:"writable"`
+	Signer   bool              `json:"signer"`
+	Accounts []json.RawMessage `json:"accounts"`
+}
+
+// Parse decodes an IDL from JSON.
+func Parse(data []byte) (*IDL, error) {
+	var raw rawIDL
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	idl := &IDL{Address: raw.Address, Name: raw.Name}
+	if idl.Address == "" {
+		idl.Address = raw.Metadata.Address
+	}
+	if idl.Name == "" {
+		idl.Name = raw.Metadata.Name
+	}
+
+	for _, t := range raw.Types {
+		def := TypeDef{Name: t.Name, Kind: t.Type.Kind}
+		var err error
+		if def.Fields, err = parseFields(t.Type.Fields); err != nil {
+			return nil, fmt.Errorf("type %s: %w", t.Name, err)
+		}
+		for _, v := range t.Type.Variants {
+			fields, err := parseFields(v.Fields)
+			if err != nil {
+				return nil, fmt.Errorf("type %s variant %s: %w", t.Name, v.Name, err)
+			}
+			def.Variants = append(def.Variants, Variant{Name: v.Name, Fields: fields})
+		}
+		idl.Types = append(idl.Types, def)
+	}
+
+	for _, in := range raw.Instructions {
+		inst := Instruction{Name: in.Name, Discriminator: intsToBytes(in.RawDisc)}
+		if len(inst.Discriminator) == 0 {
+			d := InstructionDiscriminator(snakeCase(in.Name))
+			inst.Discriminator = d[:]
+		}
+		accounts, err := parseAccounts(in.Accounts)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %s: %w", in.Name, err)
+		}
+		inst.Accounts = accounts
+		if inst.Args, err = parseFields(in.Args); err != nil {
+			return nil, fmt.Errorf("instruction %s: %w", in.Name, err)
+		}
+		idl.Instructions = append(idl.Instructions, inst)
+	}
+
+	for _, ev := range raw.Events {
+		event := Event{Name: ev.Name, Discriminator: intsToBytes(ev.RawDisc)}
+		if len(event.Discriminator) == 0 {
+			d := EventDiscriminator(ev.Name)
+			event.Discriminator = d[:]
+		}
+		var err error
+		if event.Fields, err = parseFields(ev.Fields); err != nil {
+			return nil, fmt.Errorf("event %s: %w", ev.Name, err)
+		}
+		if len(ev.Fields) == 0 {
+			if def := idl.typeDef(ev.Name); def != nil {
+				event.Fields = def.Fields
+			}
+		}
+		idl.Events = append(idl.Events, event)
+	}
+	return idl, nil
+}
+
+func (idl *IDL) typeDef(name string) *TypeDef {
+	for i := range idl.Types {
+		if idl.Types[i].Name == name {
+			return &idl.Types[i]
+		}
+	}
+	return nil
+}
+
+// parseAccounts flattens nested account groups (legacy IDLs).
+func parseAccounts(raws []json.RawMessage) ([]Account, error) {
+	var out []Account
+	for _, r := range raws {
+		var a rawAccount
+		if err := json.Unmarshal(r, &a); err != nil {
+			return nil, err
+		}
+		if len(a.Accounts) > 0 {
+			nested, err := parseAccounts(a.Accounts)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, nested...)
+			continue
+		}
+		out = append(out, Account{Name: a.Name, Writable: a.IsMut || a.Writable, Signer: a.IsSigner || a.Signer})
+	}
+	return out, nil
+}
+
+// parseFields accepts named fields ({"name","type"}) and tuple fields (bare types).
+func parseFields(raws []json.RawMessage) ([]Field, error) {
+	var out []Field
+	for _, r := range raws {
+		var named struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		}
+		if json.Unmarshal(r, &named) == nil && named.Name != "" && len(named.Type) > 0 {
+			t, err := parseType(named.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", named.Name, err)
+			}
+			out = append(out, Field{Name: named.Name, Type: t})
+			continue
+		}
+		t, err := parseType(r)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Field{Type: t})
+	}
+	return out, nil
+}
+
+func parseType(r json.RawMessage) (Type, error) {
+	var prim string
+	if err := json.Unmarshal(r, &prim); err == nil {
+		if prim == "publicKey" {
+			prim = "pubkey"
+		}
+		return Type{Kind: prim}, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(r, &obj); err != nil {
+		return Type{}, fmt.Errorf("unsupported type %s", r)
+	}
+	for _, kind := range []string{"vec", "option", "coption"} {
+		if inner, ok := obj[kind]; ok {
+			elem, err := parseType(inner)
+			if err != nil {
+				return Type{}, err
+			}
+			return Type{Kind: kind, Elem: &elem}, nil
+		}
+	}
+	if arr, ok := obj["array"]; ok {
+		var parts []json.RawMessage
+		if err := json.Unmarshal(arr, &parts); err != nil || len(parts) != 2 {
+			return Type{}, fmt.Errorf("unsupported array type %s", arr)
+		}
+		elem, err := parseType(parts[0])
+		if err != nil {
+			return Type{}, err
+		}
+		var n int
+		if err := json.Unmarshal(parts[1], &n); err != nil {
+			return Type{}, fmt.Errorf("unsupported array length %s", parts[1])
+		}
+		return Type{Kind: "array", Elem: &elem, Len: n}, nil
+	}
+	if def, ok := obj["defined"]; ok {
+		var name string
+		if json.Unmarshal(def, &name) != nil {
+			var named struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(def, &named); err != nil {
+				return Type{}, fmt.Errorf("unsupported defined type %s", def)
+			}
+			name = named.Name
+		}
+		return Type{Kind: "defined", Defined: name}, nil
+	}
+	return Type{}, fmt.Errorf("unsupported type %s", r)
+}
+
+func intsToBytes(ints []int) []byte {
+	if len(ints) == 0 {
+		return nil
+	}
+	out := make([]byte, len(ints))
+	for i, v := range ints {
+		out[i] = byte(v)
+	}
+	return out
+}
+
+// snakeCase converts legacy camelCase instruction names the way Anchor does
+// before hashing ("swapBaseIn" → "swap_base_in").
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,200 @@
+package idl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// legacyIDL is a pre-0.30 IDL: camelCase names, isMut/isSigner, nested
+// account groups, inline event fields and metadata.address.
+const legacyIDL = `{
+  "version": "0.1.0",
+  "name": "pump",
+  "instructions": [{
+    "name": "buy",
+    "accounts": [
+      {"name": "global", "isMut": false, "isSigner": false},
+      {"name": "curve", "accounts": [{"name": "bondingCurve", "isMut": true, "isSigner": false}]},
+      {"name": "user", "isMut": true, "isSigner": true}
+    ],
+    "args": [{"name": "amount", "type": "u64"}, {"name": "maxSolCost", "type": "u64"}]
+  }],
+  "events": [{
+    "name": "TradeEvent",
+    "fields": [
+      {"name": "mint", "type": "publicKey", "index": false},
+      {"name": "solAmount", "type": "u64", "index": false},
+      {"name": "isBuy", "type": "bool", "index": false}
+    ]
+  }],
+  "metadata": {"address": "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P"}
+}`
+
+// modernIDL is a 0.30+ IDL with explicit discriminators and event fields in
+// the types section.
+const modernIDL = `{
+  "address": "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",
+  "metadata": {"name": "venue", "version": "0.1.0", "spec": "0.1.0"},
+  "instructions": [{
+    "name": "configure",
+    "discriminator": [1, 2, 3, 4, 5, 6, 7, 8],
+    "accounts": [{"name": "admin", "writable": true, "signer": true}],
+    "args": [{"name": "params", "type": {"defined": {"name": "Params"}}}]
+  }],
+  "events": [{"name": "Configured", "discriminator": [9, 9, 9, 9, 9, 9, 9, 9]}],
+  "types": [
+    {"name": "Params", "type": {"kind": "struct", "fields": [
+      {"name": "fee", "type": {"option": "u16"}},
+      {"name": "liquidity", "type": "u128"},
+      {"name": "mode", "type": {"defined": {"name": "Mode"}}},
+      {"name": "tags", "type": {"vec": "string"}},
+      {"name": "seed", "type": {"array": ["u8", 4]}}
+    ]}},
+    {"name": "Mode", "type": {"kind": "enum", "variants": [
+      {"name": "Off"},
+      {"name": "Fixed", "fields": ["u64"]}
+    ]}},
+    {"name": "Configured", "type": {"kind": "struct", "fields": [
+      {"name": "admin", "type": "pubkey"}
+    ]}}
+  ]
+}`
+
+func TestParse_LegacyIDL(t *testing.T) {
+	idl, err := Parse([]byte(legacyIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	prog, err := NewProgram(idl, solana.PublicKey{})
+	if err != nil {
+		t.Fatalf("NewProgram: %v", err)
+	}
+	if prog.ID.String() != "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P" {
+		t.Errorf("ID = %s", prog.ID)
+	}
+
+	buy := idl.Instructions[0]
+	if want := []byte{102, 6, 61, 18, 1, 218, 235, 234}; !bytes.Equal(buy.Discriminator, want) {
+		t.Errorf("buy discriminator = %v, want %v", buy.Discriminator, want)
+	}
+	names := []string{}
+	for _, a := range buy.Accounts {
+		names = append(names, a.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"global", "bondingCurve", "user"}) || !buy.Accounts[2].Signer {
+		t.Errorf("accounts = %+v", buy.Accounts)
+	}
+
+	data := append([]byte{}, buy.Discriminator...)
+	data = binary.LittleEndian.AppendUint64(data, 35_000_000)
+	data = binary.LittleEndian.AppendUint64(data, 1_000_000_000)
+	inst, args, err := prog.DecodeInstruction(data)
+	if err != nil {
+		t.Fatalf("DecodeInstruction: %v", err)
+	}
+	if inst.Name != "buy" || args["amount"] != uint64(35_000_000) || args["maxSolCost"] != uint64(1_000_000_000) {
+		t.Errorf("decoded %s %v", inst.Name, args)
+	}
+
+	var typed struct {
+		Amount     uint64
+		MaxSolCost uint64
+	}
+	if _, err := prog.DecodeInstructionInto(data, &typed); err != nil || typed.Amount != 35_000_000 {
+		t.Errorf("DecodeInstructionInto = %+v, %v", typed, err)
+	}
+
+	mint := solana.PublicKeyFromBytes(bytes.Repeat([]byte{3}, 32))
+	ev := append([]byte{}, EventCPIPrefix...)
+	ev = append(ev, 189, 219, 127, 211, 78, 230, 97, 238) // sha256("event:TradeEvent")[:8]
+	ev = append(ev, mint.Bytes()...)
+	ev = binary.LittleEndian.AppendUint64(ev, 1_000_000_000)
+	ev = append(ev, 1)
+	event, fields, err := prog.DecodeEvent(ev)
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	if event.Name != "TradeEvent" || fields["mint"] != mint || fields["solAmount"] != uint64(1_000_000_000) || fields["isBuy"] != true {
+		t.Errorf("decoded %s %v", event.Name, fields)
+	}
+}
+
+func TestParse_ModernIDL(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "venue.json"), []byte(modernIDL), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	progs, err := LoadPrograms(dir)
+	if err != nil {
+		t.Fatalf("LoadPrograms: %v", err)
+	}
+	if len(progs) != 1 || progs[0].IDL.Name != "venue" {
+		t.Fatalf("programs = %+v", progs)
+	}
+	prog := progs[0]
+
+	data := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	data = append(data, 1)
+	data = binary.LittleEndian.AppendUint16(data, 25) // fee: Some(25)
+	liquidity := make([]byte, 16)
+	liquidity[8] = 1 // 2^64
+	data = append(data, liquidity...)
+	data = append(data, 1) // Mode::Fixed
+	data = binary.LittleEndian.AppendUint64(data, 7)
+	data = binary.LittleEndian.AppendUint32(data, 1)
+	data = binary.LittleEndian.AppendUint32(data, 3)
+	data = append(data, "hot"...)
+	data = append(data, 0xde, 0xad, 0xbe, 0xef)
+
+	inst, args, err := prog.DecodeInstruction(data)
+	if err != nil {
+		t.Fatalf("DecodeInstruction: %v", err)
+	}
+	params, ok := args["params"].(map[string]interface{})
+	if inst.Name != "configure" || !ok {
+		t.Fatalf("decoded %s %v", inst.Name, args)
+	}
+	if params["fee"] != uint16(25) {
+		t.Errorf("fee = %v", params["fee"])
+	}
+	if l, ok := params["liquidity"].(*big.Int); !ok || l.Cmp(new(big.Int).Lsh(big.NewInt(1), 64)) != 0 {
+		t.Errorf("liquidity = %v", params["liquidity"])
+	}
+	if want := map[string]interface{}{"Fixed": map[string]interface{}{"0": uint64(7)}}; !reflect.DeepEqual(params["mode"], want) {
+		t.Errorf("mode = %v", params["mode"])
+	}
+	if !reflect.DeepEqual(params["tags"], []interface{}{"hot"}) || !bytes.Equal(params["seed"].([]byte), []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("tags/seed = %v/%v", params["tags"], params["seed"])
+	}
+
+	admin := solana.PublicKeyFromBytes(bytes.Repeat([]byte{4}, 32))
+	event, fields, err := prog.DecodeEvent(append([]byte{9, 9, 9, 9, 9, 9, 9, 9}, admin.Bytes()...))
+	if err != nil || event.Name != "Configured" || fields["admin"] != admin {
+		t.Errorf("DecodeEvent = %v, %v, %v", event, fields, err)
+	}
+
+	if _, _, err := prog.DecodeInstruction([]byte{0, 0, 0, 0, 0, 0, 0, 0}); err != ErrUnknownDiscriminator {
+		t.Errorf("unknown discriminator err = %v", err)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"buy":                     "buy",
+		"swapBaseIn":              "swap_base_in",
+		"initialize2":             "initialize2",
+		"removeLiquidityByRange2": "remove_liquidity_by_range2",
+		"setAMMConfig":            "set_amm_config",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
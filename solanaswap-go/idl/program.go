@@ -0,0 +1,131 @@
+package idl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// EventCPIPrefix tags Anchor emit_cpi! self-invocations; the event
+// discriminator and payload follow it.
+var EventCPIPrefix = []byte{228, 69, 165, 46, 81, 203, 154, 29}
+
+// ErrUnknownDiscriminator is returned when data matches no instruction or
+// event of the program.
+var ErrUnknownDiscriminator = errors.New("unknown discriminator")
+
+// Program is an IDL bound to a program ID, ready to decode.
+type Program struct {
+	ID  solana.PublicKey
+	IDL *IDL
+}
+
+// NewProgram binds idl to its address. Pass a non-zero id to override it (or
+// when the IDL has no address).
+func NewProgram(idl *IDL, id solana.PublicKey) (*Program, error) {
+	if id.IsZero() {
+		if idl.Address == "" {
+			return nil, fmt.Errorf("IDL %s has no address", idl.Name)
+		}
+		var err error
+		if id, err = solana.PublicKeyFromBase58(idl.Address); err != nil {
+			return nil, fmt.Errorf("IDL %s address: %w", idl.Name, err)
+		}
+	}
+	return &Program{ID: id, IDL: idl}, nil
+}
+
+// LoadPrograms loads every IDL in dir and binds each to its address.
+func LoadPrograms(dir string) ([]*Program, error) {
+	idls, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	programs := make([]*Program, 0, len(idls))
+	for _, idl := range idls {
+		prog, err := NewProgram(idl, solana.PublicKey{})
+		if err != nil {
+			return nil, err
+		}
+		programs = append(programs, prog)
+	}
+	return programs, nil
+}
+
+// Instruction finds the instruction data starts with.
+func (p *Program) Instruction(data []byte) (*Instruction, bool) {
+	for i := range p.IDL.Instructions {
+		inst := &p.IDL.Instructions[i]
+		if len(inst.Discriminator) > 0 && bytes.HasPrefix(data, inst.Discriminator) {
+			return inst, true
+		}
+	}
+	return nil, false
+}
+
+// Event finds the event data starts with; data may carry the emit_cpi prefix.
+func (p *Program) Event(data []byte) (*Event, []byte, bool) {
+	if len(data) >= 16 && bytes.Equal(data[:8], EventCPIPrefix) {
+		data = data[8:]
+	}
+	for i := range p.IDL.Events {
+		ev := &p.IDL.Events[i]
+		if len(ev.Discriminator) > 0 && bytes.HasPrefix(data, ev.Discriminator) {
+			return ev, data[len(ev.Discriminator):], true
+		}
+	}
+	return nil, nil, false
+}
+
+// DecodeInstruction decodes instruction data into its name and arguments.
+func (p *Program) DecodeInstruction(data []byte) (*Instruction, map[string]interface{}, error) {
+	inst, ok := p.Instruction(data)
+	if !ok {
+		return nil, nil, ErrUnknownDiscriminator
+	}
+	args, err := p.decodeFields(data[len(inst.Discriminator):], inst.Args)
+	if err != nil {
+		return inst, nil, fmt.Errorf("%s: %w", inst.Name, err)
+	}
+	return inst, args, nil
+}
+
+// DecodeInstructionInto Borsh-decodes the arguments into v.
+func (p *Program) DecodeInstructionInto(data []byte, v interface{}) (*Instruction, error) {
+	inst, ok := p.Instruction(data)
+	if !ok {
+		return nil, ErrUnknownDiscriminator
+	}
+	return inst, ag_binary.NewBorshDecoder(data[len(inst.Discriminator):]).Decode(v)
+}
+
+// DecodeEvent decodes event data (from a self-CPI or a "Program data:" log)
+// into its name and fields.
+func (p *Program) DecodeEvent(data []byte) (*Event, map[string]interface{}, error) {
+	ev, payload, ok := p.Event(data)
+	if !ok {
+		return nil, nil, ErrUnknownDiscriminator
+	}
+	fields, err := p.decodeFields(payload, ev.Fields)
+	if err != nil {
+		return ev, nil, fmt.Errorf("%s: %w", ev.Name, err)
+	}
+	return ev, fields, nil
+}
+
+// DecodeEventInto Borsh-decodes the event payload into v.
+func (p *Program) DecodeEventInto(data []byte, v interface{}) (*Event, error) {
+	ev, payload, ok := p.Event(data)
+	if !ok {
+		return nil, ErrUnknownDiscriminator
+	}
+	return ev, ag_binary.NewBorshDecoder(payload).Decode(v)
+}
+
+func (p *Program) decodeFields(data []byte, fields []Field) (map[string]interface{}, error) {
+	r := &reader{data: data, types: p.IDL}
+	return r.fields(fields)
+}
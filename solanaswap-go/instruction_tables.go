@@ -0,0 +1,221 @@
+package solanaswapgo
+
+import (
+	"github.com/gagliardetto/solana-go"
+)
+
+// InstructionKind is what an AMM instruction does to a pool.
+type InstructionKind int
+
+const (
+	InstructionOther InstructionKind = iota
+	InstructionSwap
+	InstructionAddLiquidity
+	InstructionRemoveLiquidity
+	InstructionCreatePool
+)
+
+func (k InstructionKind) String() string {
+	switch k {
+	case InstructionSwap:
+		return "swap"
+	case InstructionAddLiquidity:
+		return "add_liquidity"
+	case InstructionRemoveLiquidity:
+		return "remove_liquidity"
+	case InstructionCreatePool:
+		return "create_pool"
+	default:
+		return "other"
+	}
+}
+
+// MarshalText encodes the kind by name in JSON output.
+func (k InstructionKind) MarshalText() ([]byte, error) { return []byte(k.String()), nil }
+
+// InstructionInfo is a known instruction of a program.
+type InstructionInfo struct {
+	Name string
+	Kind InstructionKind
+}
+
+// anchorTable keys an Anchor program's instructions by discriminator.
+func anchorTable(names map[string]InstructionKind) map[[8]byte]InstructionInfo {
+	t := make(map[[8]byte]InstructionInfo, len(names))
+	for name, kind := range names {
+		t[anchorDiscriminator8(name)] = InstructionInfo{Name: name, Kind: kind}
+	}
+	return t
+}
+
+var meteoraDLMMInstructions = anchorTable(map[string]InstructionKind{
+	"swap":                               InstructionSwap,
+	"swap2":                              InstructionSwap,
+	"swap_exact_out":                     InstructionSwap,
+	"swap_exact_out2":                    InstructionSwap,
+	"swap_with_price_impact":             InstructionSwap,
+	"swap_with_price_impact2":            InstructionSwap,
+	"add_liquidity":                      InstructionAddLiquidity,
+	"add_liquidity2":                     InstructionAddLiquidity,
+	"add_liquidity_by_weight":            InstructionAddLiquidity,
+	"add_liquidity_by_strategy":          InstructionAddLiquidity,
+	"add_liquidity_by_strategy2":         InstructionAddLiquidity,
+	"add_liquidity_by_strategy_one_side": InstructionAddLiquidity,
+	"add_liquidity_one_side":             InstructionAddLiquidity,
+	"add_liquidity_one_side_precise":     InstructionAddLiquidity,
+	"add_liquidity_one_side_precise2":    InstructionAddLiquidity,
+	"remove_liquidity":                   InstructionRemoveLiquidity,
+	"remove_liquidity2":                  InstructionRemoveLiquidity,
+	"remove_liquidity_by_range":          InstructionRemoveLiquidity,
+	"remove_liquidity_by_range2":         InstructionRemoveLiquidity,
+	"remove_all_liquidity":               InstructionRemoveLiquidity,
+	"claim_fee":                          InstructionOther,
+	"claim_fee2":                         InstructionOther,
+	"claim_reward":                       InstructionOther,
+	"claim_reward2":                      InstructionOther,
+	"close_position":                     InstructionOther,
+	"close_position2":                    InstructionOther,
+	"initialize_position":                InstructionOther,
+	"initialize_lb_pair":                 InstructionOther,
+	"initialize_lb_pair2":                InstructionOther,
+})
+
+// instructionTables maps (program, discriminator) to the instruction for the
+// Anchor AMMs and launchpads DetectLiquidityOp classifies.
+var instructionTables = map[solana.PublicKey]map[[8]byte]InstructionInfo{
+	PUMPFUN_AMM_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"buy":                InstructionSwap,
+		"sell":               InstructionSwap,
+		"buy_exact_quote_in": InstructionSwap,
+		"deposit":            InstructionAddLiquidity,
+		"withdraw":           InstructionRemoveLiquidity,
+		"create_pool":        InstructionCreatePool,
+	}),
+	PUMP_FUN_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"buy":              InstructionSwap,
+		"sell":             InstructionSwap,
+		"buy_exact_sol_in": InstructionSwap,
+		"create":           InstructionOther,
+		"create_v2":        InstructionOther,
+		"migrate":          InstructionCreatePool,
+	}),
+	METEORA_PROGRAM_ID:      meteoraDLMMInstructions,
+	METEORA_DLMM_PROGRAM_ID: meteoraDLMMInstructions,
+	METEORA_POOLS_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"swap":                         InstructionSwap,
+		"add_balance_liquidity":        InstructionAddLiquidity,
+		"add_imbalance_liquidity":      InstructionAddLiquidity,
+		"bootstrap_liquidity":          InstructionAddLiquidity,
+		"remove_balance_liquidity":     InstructionRemoveLiquidity,
+		"remove_liquidity_single_side": InstructionRemoveLiquidity,
+		"initialize_permissionless_constant_product_pool_with_config":  InstructionCreatePool,
+		"initialize_permissionless_constant_product_pool_with_config2": InstructionCreatePool,
+		"initialize_permissionless_pool":                               InstructionCreatePool,
+		"claim_fee":                                                    InstructionOther,
+	}),
+	METEORA_DAMM_V2_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"swap":                                InstructionSwap,
+		"swap2":                               InstructionSwap,
+		"add_liquidity":                       InstructionAddLiquidity,
+		"remove_liquidity":                    InstructionRemoveLiquidity,
+		"remove_all_liquidity":                InstructionRemoveLiquidity,
+		"initialize_pool":                     InstructionCreatePool,
+		"initialize_pool_with_dynamic_config": InstructionCreatePool,
+		"initialize_customizable_pool":        InstructionCreatePool,
+		"create_position":                     InstructionOther,
+		"close_position":                      InstructionOther,
+		"claim_position_fee":                  InstructionOther,
+		"claim_reward":                        InstructionOther,
+	}),
+	METEORA_DBC_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"swap":                                   InstructionSwap,
+		"swap2":                                  InstructionSwap,
+		"initialize_virtual_pool_with_spl_token": InstructionOther,
+		"initialize_virtual_pool_with_token2022": InstructionOther,
+		"migration_damm_v2":                      InstructionCreatePool,
+		"migrate_meteora_damm":                   InstructionCreatePool,
+	}),
+	ORCA_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"swap":                  InstructionSwap,
+		"swap_v2":               InstructionSwap,
+		"two_hop_swap":          InstructionSwap,
+		"two_hop_swap_v2":       InstructionSwap,
+		"increase_liquidity":    InstructionAddLiquidity,
+		"increase_liquidity_v2": InstructionAddLiquidity,
+		"decrease_liquidity":    InstructionRemoveLiquidity,
+		"decrease_liquidity_v2": InstructionRemoveLiquidity,
+		"initialize_pool":       InstructionCreatePool,
+		"initialize_pool_v2":    InstructionCreatePool,
+		"open_position":         InstructionOther,
+		"close_position":        InstructionOther,
+		"collect_fees":          InstructionOther,
+		"collect_fees_v2":       InstructionOther,
+		"collect_reward":        InstructionOther,
+		"collect_reward_v2":     InstructionOther,
+	}),
+	RAYDIUM_CPMM_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"swap_base_input":  InstructionSwap,
+		"swap_base_output": InstructionSwap,
+		"deposit":          InstructionAddLiquidity,
+		"withdraw":         InstructionRemoveLiquidity,
+		"initialize":       InstructionCreatePool,
+	}),
+	RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"swap":                           InstructionSwap,
+		"swap_v2":                        InstructionSwap,
+		"swap_router_base_in":            InstructionSwap,
+		"increase_liquidity":             InstructionAddLiquidity,
+		"increase_liquidity_v2":          InstructionAddLiquidity,
+		"open_position":                  InstructionAddLiquidity,
+		"open_position_v2":               InstructionAddLiquidity,
+		"open_position_with_token22_nft": InstructionAddLiquidity,
+		"decrease_liquidity":             InstructionRemoveLiquidity,
+		"decrease_liquidity_v2":          InstructionRemoveLiquidity,
+		"create_pool":                    InstructionOther,
+		"close_position":                 InstructionOther,
+	}),
+	RAYDIUM_LAUNCHLAB_PROGRAM_ID: anchorTable(map[string]InstructionKind{
+		"buy_exact_in":               InstructionSwap,
+		"buy_exact_out":              InstructionSwap,
+		"sell_exact_in":              InstructionSwap,
+		"sell_exact_out":             InstructionSwap,
+		"initialize":                 InstructionOther,
+		"initialize_v2":              InstructionOther,
+		"initialize_with_token_2022": InstructionOther,
+		"migrate_to_amm":             InstructionCreatePool,
+		"migrate_to_cpswap":          InstructionCreatePool,
+	}),
+}
+
+// nativeInstructionTables maps (program, tag byte) for non-Anchor AMMs.
+var nativeInstructionTables = map[solana.PublicKey]map[byte]InstructionInfo{
+	RAYDIUM_V4_PROGRAM_ID: {
+		1:  {"initialize2", InstructionCreatePool},
+		3:  {"deposit", InstructionAddLiquidity},
+		4:  {"withdraw", InstructionRemoveLiquidity},
+		9:  {"swap_base_in", InstructionSwap},
+		11: {"swap_base_out", InstructionSwap},
+		16: {"swap_base_in_v2", InstructionSwap},
+		17: {"swap_base_out_v2", InstructionSwap},
+	},
+}
+
+// lookupInstruction names inst from its program's table.
+func (p *Parser) lookupInstruction(inst solana.CompiledInstruction) (InstructionInfo, bool) {
+	if int(inst.ProgramIDIndex) >= len(p.allAccountKeys) {
+		return InstructionInfo{}, false
+	}
+	progID := p.allAccountKeys[inst.ProgramIDIndex]
+	if table, ok := instructionTables[progID]; ok {
+		if disc, ok := prefix8(inst.Data); ok {
+			info, ok := table[disc]
+			return info, ok
+		}
+		return InstructionInfo{}, false
+	}
+	if table, ok := nativeInstructionTables[progID]; ok && len(inst.Data) > 0 {
+		info, ok := table[inst.Data[0]]
+		return info, ok
+	}
+	return InstructionInfo{}, false
+}
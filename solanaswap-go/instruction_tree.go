@@ -0,0 +1,121 @@
+package solanaswapgo
+
+import (
+	"github.com/gagliardetto/solana-go"
+)
+
+// InstructionNode is one instruction in the CPI tree of a transaction.
+type InstructionNode struct {
+	Instruction solana.CompiledInstruction
+	ProgramID   solana.PublicKey
+	OuterIndex  int
+	InnerIndex  int // position in the inner instruction set; -1 for the outer instruction
+	StackHeight int // 1 for outer instructions
+	Parent      *InstructionNode
+	Children    []*InstructionNode
+}
+
+// Walk visits n and its descendants depth-first in execution order; returning
+// false from fn skips the children of that node.
+func (n *InstructionNode) Walk(fn func(*InstructionNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// InstructionTree returns one root per outer instruction with the CPIs nested
+// by stackHeight. Inner instructions without a stack height (transactions from
+// before it was recorded) become direct children of their outer instruction.
+func (p *Parser) InstructionTree() []*InstructionNode {
+	if p.instructionTree != nil {
+		return p.instructionTree
+	}
+	roots := make([]*InstructionNode, len(p.txInfo.Message.Instructions))
+	for i, outer := range p.txInfo.Message.Instructions {
+		roots[i] = &InstructionNode{
+			Instruction: outer,
+			ProgramID:   p.allAccountKeys[outer.ProgramIDIndex],
+			OuterIndex:  i,
+			InnerIndex:  -1,
+			StackHeight: 1,
+		}
+	}
+	if p.txMeta != nil {
+		for _, set := range p.txMeta.InnerInstructions {
+			if int(set.Index) >= len(roots) {
+				continue
+			}
+			stack := []*InstructionNode{roots[set.Index]}
+			for j, ri := range set.Instructions {
+				height := int(ri.StackHeight)
+				if height < 2 {
+					height = 2
+				}
+				for len(stack) > 1 && stack[len(stack)-1].StackHeight >= height {
+					stack = stack[:len(stack)-1]
+				}
+				parent := stack[len(stack)-1]
+				inst := p.convertRPCToSolanaInstruction(ri)
+				node := &InstructionNode{
+					Instruction: inst,
+					ProgramID:   p.allAccountKeys[inst.ProgramIDIndex],
+					OuterIndex:  int(set.Index),
+					InnerIndex:  j,
+					StackHeight: height,
+					Parent:      parent,
+				}
+				parent.Children = append(parent.Children, node)
+				stack = append(stack, node)
+			}
+		}
+	}
+	p.instructionTree = roots
+	return roots
+}
+
+// hasStackHeights reports whether the CPIs under the outer instruction at
+// index carry stack heights, i.e. whether its tree reflects real nesting.
+func (p *Parser) hasStackHeights(index int) bool {
+	if p.txMeta == nil {
+		return false
+	}
+	for _, set := range p.txMeta.InnerInstructions {
+		if int(set.Index) != index {
+			continue
+		}
+		for _, ri := range set.Instructions {
+			if ri.StackHeight > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TokenTransfersUnderNode decodes the Transfer/TransferChecked CPIs made by the
+// invocation at node, including through helper programs it calls, but not
+// those made by other AMM invocations nested below it.
+func (p *Parser) TokenTransfersUnderNode(node *InstructionNode, swapType SwapType) []SwapData {
+	var legs []SwapData
+	for _, c := range node.Children {
+		c.Walk(func(n *InstructionNode) bool {
+			if p.isHopInvocation(n.Instruction) {
+				return false
+			}
+			if p.isTransferCheck(n.Instruction) {
+				if tr := p.processTransferCheck(n.Instruction); tr != nil {
+					legs = append(legs, SwapData{Type: swapType, Data: tr})
+				}
+			} else if p.isTransfer(n.Instruction) {
+				if tr := p.processTransfer(n.Instruction); tr != nil {
+					legs = append(legs, SwapData{Type: swapType, Data: tr})
+				}
+			}
+			return true
+		})
+	}
+	return legs
+}
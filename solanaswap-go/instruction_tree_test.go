@@ -0,0 +1,84 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// newTwoHopRouterParser builds a bot-wrapped route: Raydium V4 (SOL→USDC)
+// then Orca (USDC→BONK), each with its transfers nested one level below it.
+func newTwoHopRouterParser(t *testing.T, withStackHeights bool) *Parser {
+	t.Helper()
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	keys := []solana.PublicKey{
+		testPayer, testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), testKey(6), testKey(7), // 0-7
+		solana.TokenProgramID, RAYDIUM_V4_PROGRAM_ID, ORCA_PROGRAM_ID, BANANA_GUN_PROGRAM_ID, // 8-11
+	}
+	h := func(height uint16) uint16 {
+		if withStackHeights {
+			return height
+		}
+		return 0
+	}
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 9, Accounts: []uint16{8, 7}, Data: []byte{9}, StackHeight: h(2)},
+				{ProgramIDIndex: 8, Accounts: []uint16{1, 3, 0}, Data: transferData(1_000_000_000), StackHeight: h(3)},
+				{ProgramIDIndex: 8, Accounts: []uint16{4, 2, 7}, Data: transferData(150_000_000), StackHeight: h(3)},
+				{ProgramIDIndex: 10, Accounts: []uint16{8, 7}, Data: orcaSwapV2Disc[:], StackHeight: h(2)},
+				{ProgramIDIndex: 8, Accounts: []uint16{2, 5, 0}, Data: transferData(150_000_000), StackHeight: h(3)},
+				{ProgramIDIndex: 8, Accounts: []uint16{6, 1, 7}, Data: transferData(7_000_000), StackHeight: h(3)},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(1, sol, testPayer, "0", 9),
+			tokenBalance(2, usdc, testPayer, "0", 6),
+			tokenBalance(3, sol, testKey(7), "1000000000", 9),
+			tokenBalance(4, usdc, testKey(7), "0", 6),
+			tokenBalance(5, usdc, testKey(7), "150000000", 6),
+			tokenBalance(6, testBonkMint, testKey(7), "0", 5),
+		},
+	}
+	return newTestParser(t, keys, []solana.CompiledInstruction{{ProgramIDIndex: 11, Accounts: []uint16{0}}}, meta)
+}
+
+func TestInstructionTree_StackHeights(t *testing.T) {
+	p := newTwoHopRouterParser(t, true)
+	roots := p.InstructionTree()
+	if len(roots) != 1 || len(roots[0].Children) != 2 {
+		t.Fatalf("root children = %d, want 2 AMM calls", len(roots[0].Children))
+	}
+	for i, want := range []solana.PublicKey{RAYDIUM_V4_PROGRAM_ID, ORCA_PROGRAM_ID} {
+		amm := roots[0].Children[i]
+		if !amm.ProgramID.Equals(want) || len(amm.Children) != 2 || amm.Parent != roots[0] || amm.StackHeight != 2 {
+			t.Errorf("child %d = %s with %d children", i, amm.ProgramID, len(amm.Children))
+		}
+		if legs := p.TokenTransfersUnderNode(amm, RAYDIUM); len(legs) != 2 {
+			t.Errorf("child %d: %d transfers, want 2", i, len(legs))
+		}
+	}
+}
+
+func TestProcessRouterSwaps_PerInvocation(t *testing.T) {
+	legs := newTwoHopRouterParser(t, true).processRouterSwaps(0)
+	want := []SwapType{RAYDIUM, RAYDIUM, ORCA, ORCA}
+	if len(legs) != len(want) {
+		t.Fatalf("got %d legs, want %d", len(legs), len(want))
+	}
+	for i, leg := range legs {
+		if leg.Type != want[i] {
+			t.Errorf("leg %d type = %s, want %s", i, leg.Type, want[i])
+		}
+	}
+
+	// Without stack heights the tree is flat and each protocol harvests the
+	// whole inner set, as before.
+	if legs := newTwoHopRouterParser(t, false).processRouterSwaps(0); len(legs) != 8 {
+		t.Errorf("flat: got %d legs, want 8", len(legs))
+	}
+}
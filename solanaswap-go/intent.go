@@ -0,0 +1,172 @@
+package solanaswapgo
+
+import (
+	"github.com/gagliardetto/solana-go"
+)
+
+// SwapIntent is what an outer swap instruction asks for, decoded from its
+// arguments and accounts alone. It needs no TransactionMeta, so it describes
+// unsigned or unsent transactions (e.g. from an aggregator quote API) before
+// they execute.
+type SwapIntent struct {
+	InstructionIndex int
+	Protocol         string // registered decoder name ("jupiter", "raydium", ...)
+
+	// SwapArgs holds the mints and amounts. Without meta a mint is only known
+	// when the instruction passes it or the transaction creates the user's
+	// associated token account for it; otherwise it is zero.
+	SwapArgs
+
+	// RoutePrograms are the AMMs the swap goes through: the venue itself, or
+	// those a router (Jupiter, OKX) passes in its remaining accounts. Resolve
+	// the address lookup tables first for v0 routes.
+	RoutePrograms []solana.PublicKey
+
+	// SourceTokenAccount and DestinationTokenAccount are the user's accounts
+	// the input leaves and the output lands in (the wallet itself for native
+	// SOL on Pump.fun and Moonshot).
+	SourceTokenAccount      solana.PublicKey
+	DestinationTokenAccount solana.PublicKey
+
+	FeePayer solana.PublicKey
+}
+
+// ParseSwapIntents decodes the declared swap of every outer instruction of a
+// router or AMM it knows. Swaps a bot wraps in its own instruction are only
+// visible through their CPIs and are not reported.
+func (p *Parser) ParseSwapIntents() ([]SwapIntent, error) {
+	var intents []SwapIntent
+	created := p.createdTokenAccountMints()
+	for i, inst := range p.txInfo.Message.Instructions {
+		args, ok := p.DecodeSwapArgs(inst)
+		if !ok {
+			continue
+		}
+		intent := SwapIntent{
+			InstructionIndex: i,
+			SwapArgs:         *args,
+			FeePayer:         p.FeePayer(),
+		}
+		if d := p.decoderFor(args.ProgramID); d != nil {
+			intent.Protocol = d.Name()
+		}
+
+		src, dst, routeStart := p.intentAccounts(inst, args)
+		intent.SourceTokenAccount = p.instructionAccount(inst, src)
+		intent.DestinationTokenAccount = p.instructionAccount(inst, dst)
+		if routeStart < 0 {
+			intent.RoutePrograms = []solana.PublicKey{args.ProgramID}
+		} else {
+			intent.RoutePrograms = p.routeAMMs(inst, routeStart)
+		}
+
+		if intent.InputMint.IsZero() {
+			intent.InputMint = created[intent.SourceTokenAccount]
+		}
+		if intent.OutputMint.IsZero() {
+			intent.OutputMint = created[intent.DestinationTokenAccount]
+		}
+		intents = append(intents, intent)
+	}
+	return intents, nil
+}
+
+// intentAccounts gives the instruction account positions of the user's source
+// and destination accounts (-1 when unknown) and, for routers, where the
+// remaining (per-hop) accounts start; routeStart is -1 for direct AMM swaps.
+func (p *Parser) intentAccounts(inst solana.CompiledInstruction, args *SwapArgs) (src, dst, routeStart int) {
+	n := len(inst.Accounts)
+	switch progID := args.ProgramID; {
+	case progID.Equals(RAYDIUM_V4_PROGRAM_ID):
+		return n - 3, n - 2, -1
+	case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID):
+		return 4, 5, -1
+	case progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
+		return 3, 4, -1
+	case progID.Equals(METEORA_PROGRAM_ID):
+		return 4, 5, -1
+	case progID.Equals(ORCA_PROGRAM_ID):
+		ownerA, ownerB := 3, 5 // swap: token_owner_account_a / _b
+		if disc, _ := prefix8(inst.Data); disc == orcaSwapV2Disc {
+			ownerA, ownerB = 7, 9
+		}
+		if len(inst.Data) > 41 && inst.Data[41] == 0 { // b → a
+			return ownerB, ownerA, -1
+		}
+		return ownerA, ownerB, -1
+	case progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
+		// user_base_token_account 5, user_quote_token_account 6
+		if disc, _ := prefix8(inst.Data); disc == pumpSellDisc {
+			return 5, 6, -1
+		}
+		return 6, 5, -1
+	case progID.Equals(PUMP_FUN_PROGRAM_ID):
+		// associated_user 5, user 6
+		if disc, _ := prefix8(inst.Data); disc == pumpSellDisc {
+			return 5, 6, -1
+		}
+		return 6, 5, -1
+	case progID.Equals(MOONSHOT_PROGRAM_ID):
+		// sender 0, sender_token_account 1
+		if disc, _ := prefix8(inst.Data); disc == [8]byte(MOONSHOT_SELL_INSTRUCTION) {
+			return 1, 0, -1
+		}
+		return 0, 1, -1
+	case progID.Equals(JUPITER_PROGRAM_ID):
+		disc, _ := prefix8(inst.Data)
+		switch disc {
+		case jupiterRouteDisc:
+			return 2, p.jupiterDestination(inst), 9
+		case jupiterExactOutRouteDisc:
+			return 2, p.jupiterDestination(inst), 11
+		default: // shared-accounts routes
+			return 3, 6, 13
+		}
+	case progID.Equals(OKX_DEX_ROUTER_PROGRAM_ID):
+		return 1, 2, 5
+	}
+	return -1, -1, -1
+}
+
+// jupiterDestination is destination_token_account (4) of a non-shared route,
+// or user_destination_token_account (3) when it is None (Jupiter's own ID).
+func (p *Parser) jupiterDestination(inst solana.CompiledInstruction) int {
+	if p.instructionAccount(inst, 4).Equals(JUPITER_PROGRAM_ID) {
+		return 3
+	}
+	return 4
+}
+
+// routeAMMs lists, in order and without repeats, the registered AMM programs a
+// router instruction passes from position start on.
+func (p *Parser) routeAMMs(inst solana.CompiledInstruction, start int) []solana.PublicKey {
+	var amms []solana.PublicKey
+	for pos := start; pos < len(inst.Accounts); pos++ {
+		k := p.instructionAccount(inst, pos)
+		if d := p.decoderFor(k); d != nil && d.Role() == RoleAMM && !containsKey(amms, k) {
+			amms = append(amms, k)
+		}
+	}
+	return amms
+}
+
+// createdTokenAccountMints maps the associated token accounts the transaction
+// creates (Create / CreateIdempotent) to their mints.
+func (p *Parser) createdTokenAccountMints() map[solana.PublicKey]solana.PublicKey {
+	mints := make(map[solana.PublicKey]solana.PublicKey)
+	for _, inst := range p.txInfo.Message.Instructions {
+		if int(inst.ProgramIDIndex) >= len(p.allAccountKeys) ||
+			!p.allAccountKeys[inst.ProgramIDIndex].Equals(solana.SPLAssociatedTokenAccountProgramID) {
+			continue
+		}
+		// funding, associated account, wallet, mint, ...
+		if len(inst.Data) > 0 && inst.Data[0] > 1 {
+			continue // RecoverNested
+		}
+		ata, mint := p.instructionAccount(inst, 1), p.instructionAccount(inst, 3)
+		if !ata.IsZero() && !mint.IsZero() {
+			mints[ata] = mint
+		}
+	}
+	return mints
+}
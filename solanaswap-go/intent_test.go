@@ -0,0 +1,111 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestParseSwapIntents_JupiterSharedRoute(t *testing.T) {
+	usdc := solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	keys := []solana.PublicKey{
+		testPayer, testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), // 0-5
+		NATIVE_SOL_MINT_PROGRAM_ID, usdc, JUPITER_PROGRAM_ID, // 6-8
+		RAYDIUM_V4_PROGRAM_ID, ORCA_PROGRAM_ID, solana.TokenProgramID, // 9-11
+	}
+	// token_program, program_authority, user_transfer_authority, source,
+	// program_source, program_destination, destination, source_mint,
+	// destination_mint, platform_fee, token_2022, event_authority, program,
+	// then the per-hop accounts.
+	accounts := []uint16{11, 1, 0, 2, 3, 4, 5, 6, 7, 8, 8, 1, 8, 9, 3, 10, 4, 9}
+	data := append(jupiterSharedAccountsRouteDisc[:], 0, 0, 0, 0) // route_plan elided
+	data = binary.LittleEndian.AppendUint64(data, 1_000_000_000)
+	data = binary.LittleEndian.AppendUint64(data, 150_000_000)
+	data = binary.LittleEndian.AppendUint16(data, 50)
+	data = append(data, 0)
+
+	p := newTestParser(t, keys, []solana.CompiledInstruction{{ProgramIDIndex: 8, Accounts: accounts, Data: data}}, nil)
+	intents, err := p.ParseSwapIntents()
+	if err != nil || len(intents) != 1 {
+		t.Fatalf("intents = %+v, %v", intents, err)
+	}
+	in := intents[0]
+	if in.Protocol != PROTOCOL_JUPITER || !in.InputMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || !in.OutputMint.Equals(usdc) {
+		t.Errorf("intent = %+v", in)
+	}
+	if in.AmountIn != 1_000_000_000 || in.MinAmountOut != 149_250_000 || in.SlippageBps != 50 {
+		t.Errorf("amounts = %d/%d (%d bps)", in.AmountIn, in.MinAmountOut, in.SlippageBps)
+	}
+	if !in.SourceTokenAccount.Equals(testKey(2)) || !in.DestinationTokenAccount.Equals(testKey(5)) {
+		t.Errorf("accounts = %s → %s", in.SourceTokenAccount, in.DestinationTokenAccount)
+	}
+	if len(in.RoutePrograms) != 2 || !in.RoutePrograms[0].Equals(RAYDIUM_V4_PROGRAM_ID) || !in.RoutePrograms[1].Equals(ORCA_PROGRAM_ID) {
+		t.Errorf("route = %v", in.RoutePrograms)
+	}
+}
+
+func TestParseSwapIntents_PumpfunBuy(t *testing.T) {
+	mint := testKey(9)
+	keys := []solana.PublicKey{
+		testPayer, testKey(1), testKey(2), testKey(3), mint, testKey(5), // 0-5
+		PUMP_FUN_PROGRAM_ID, solana.SPLAssociatedTokenAccountProgramID, solana.SystemProgramID, solana.TokenProgramID, // 6-9
+	}
+	data := append(pumpBuyDisc[:], make([]byte, 16)...)
+	binary.LittleEndian.PutUint64(data[8:], 5_000_000_000)
+	binary.LittleEndian.PutUint64(data[16:], 110_000_000)
+	outer := []solana.CompiledInstruction{
+		// CreateIdempotent(funding, ata, wallet, mint, system, token)
+		{ProgramIDIndex: 7, Accounts: []uint16{0, 5, 0, 4, 8, 9}, Data: []byte{1}},
+		// global, fee_recipient, mint, bonding_curve, associated_bonding_curve, associated_user, user
+		{ProgramIDIndex: 6, Accounts: []uint16{1, 2, 4, 3, 3, 5, 0, 8, 9}, Data: data},
+	}
+	intents, err := newTestParser(t, keys, outer, nil).ParseSwapIntents()
+	if err != nil || len(intents) != 1 {
+		t.Fatalf("intents = %+v, %v", intents, err)
+	}
+	in := intents[0]
+	if in.InstructionIndex != 1 || !in.ExactOut || in.AmountOut != 5_000_000_000 || in.MaxAmountIn != 110_000_000 {
+		t.Errorf("intent = %+v", in)
+	}
+	if !in.InputMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || !in.OutputMint.Equals(mint) {
+		t.Errorf("mints = %s → %s", in.InputMint, in.OutputMint)
+	}
+	if !in.SourceTokenAccount.Equals(testPayer) || !in.DestinationTokenAccount.Equals(testKey(5)) {
+		t.Errorf("accounts = %s → %s", in.SourceTokenAccount, in.DestinationTokenAccount)
+	}
+	if len(in.RoutePrograms) != 1 || !in.RoutePrograms[0].Equals(PUMP_FUN_PROGRAM_ID) {
+		t.Errorf("route = %v", in.RoutePrograms)
+	}
+}
+
+func TestParseSwapIntents_RaydiumMintFromCreatedATA(t *testing.T) {
+	mint := testKey(30)
+	keys := []solana.PublicKey{testPayer}
+	for i := byte(1); i <= 18; i++ {
+		keys = append(keys, testKey(i))
+	}
+	keys = append(keys, RAYDIUM_V4_PROGRAM_ID, solana.SPLAssociatedTokenAccountProgramID, mint) // 19-21
+	accounts := make([]uint16, 18)
+	for i := range accounts {
+		accounts[i] = uint16(i + 1)
+	}
+	data := []byte{9}
+	data = binary.LittleEndian.AppendUint64(data, 2_000)
+	data = binary.LittleEndian.AppendUint64(data, 1_900)
+	outer := []solana.CompiledInstruction{
+		{ProgramIDIndex: 20, Accounts: []uint16{0, 17, 0, 21}, Data: []byte{1}}, // destination ATA
+		{ProgramIDIndex: 19, Accounts: accounts, Data: data},
+	}
+	intents, _ := newTestParser(t, keys, outer, nil).ParseSwapIntents()
+	if len(intents) != 1 {
+		t.Fatalf("got %d intents", len(intents))
+	}
+	in := intents[0]
+	if in.AmountIn != 2_000 || in.MinAmountOut != 1_900 || !in.OutputMint.Equals(mint) || !in.InputMint.IsZero() {
+		t.Errorf("intent = %+v", in)
+	}
+	if !in.DestinationTokenAccount.Equals(testKey(17)) || !in.SourceTokenAccount.Equals(testKey(16)) {
+		t.Errorf("accounts = %s → %s", in.SourceTokenAccount, in.DestinationTokenAccount)
+	}
+}
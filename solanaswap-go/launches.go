@@ -0,0 +1,277 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"time"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Launch platforms reported in TokenLaunch.Platform.
+const (
+	LAUNCH_PUMPFUN           = "pumpfun"
+	LAUNCH_RAYDIUM_LAUNCHLAB = "raydium_launchlab"
+	LAUNCH_METEORA_DBC       = "meteora_dbc"
+)
+
+// TokenLaunch is a token created on a launchpad bonding curve.
+type TokenLaunch struct {
+	Platform  string
+	ProgramID solana.PublicKey
+	Mint      solana.PublicKey
+	QuoteMint solana.PublicKey
+	Pool      solana.PublicKey // bonding curve / pool state
+	Creator   solana.PublicKey
+
+	Name     string
+	Symbol   string
+	URI      string
+	Decimals uint8
+
+	// InitialBuy is what the creator bought in the creation transaction; nil
+	// when they didn't.
+	InitialBuy *InitialBuy
+
+	InstructionIndex int
+	Signature        solana.Signature
+	Slot             uint64
+	Timestamp        time.Time
+}
+
+// InitialBuy is the creator's first purchase of a launched token.
+type InitialBuy struct {
+	QuoteMint   solana.PublicKey
+	QuoteAmount uint64
+	TokenAmount uint64
+}
+
+var (
+	launchLabInitializeDiscs = map[[8]byte]bool{
+		anchorDiscriminator8("initialize"):                 true,
+		anchorDiscriminator8("initialize_v2"):              true,
+		anchorDiscriminator8("initialize_with_token_2022"): true,
+	}
+	meteoraDBCInitializeDiscs = map[[8]byte]bool{
+		anchorDiscriminator8("initialize_virtual_pool_with_spl_token"): true,
+		anchorDiscriminator8("initialize_virtual_pool_with_token2022"): true,
+	}
+)
+
+const pumpfunTokenDecimals = 6
+
+// ParseLaunches returns the token launches in the transaction: Pump.fun
+// CreateEvents, Raydium LaunchLab initialize and Meteora DBC pool creation.
+func (p *Parser) ParseLaunches() ([]TokenLaunch, error) {
+	if p.IsFailed() {
+		return nil, nil
+	}
+
+	var launches []TokenLaunch
+	for i, outer := range p.txInfo.Message.Instructions {
+		for _, inst := range append([]solana.CompiledInstruction{outer}, p.getInnerInstructions(i)...) {
+			var launch *TokenLaunch
+			switch progID := p.allAccountKeys[inst.ProgramIDIndex]; {
+			case progID.Equals(PUMP_FUN_PROGRAM_ID):
+				launch = p.pumpfunLaunch(inst)
+			case progID.Equals(RAYDIUM_LAUNCHLAB_PROGRAM_ID):
+				launch = p.launchLabLaunch(inst)
+			case progID.Equals(METEORA_DBC_PROGRAM_ID):
+				launch = p.meteoraDBCLaunch(inst)
+			}
+			if launch == nil {
+				continue
+			}
+			launch.InstructionIndex = i
+			launch.Slot = p.slot
+			if len(p.txInfo.Signatures) > 0 {
+				launch.Signature = p.txInfo.Signatures[0]
+			}
+			if p.blockTime != nil {
+				launch.Timestamp = p.blockTime.Time()
+			}
+			launches = append(launches, *launch)
+		}
+	}
+	return launches, nil
+}
+
+// pumpfunLaunch decodes a CreateEvent; the creator's buy comes from the
+// TradeEvents of the same mint.
+func (p *Parser) pumpfunLaunch(inst solana.CompiledInstruction) *TokenLaunch {
+	if len(inst.Data) < 16 || !bytes.Equal(inst.Data[:16], PumpfunCreateEventDiscriminator[:]) {
+		return nil
+	}
+	event, err := parsePumpfunCreateEvent(inst.Data[16:])
+	if err != nil {
+		p.Log.Errorf("error processing Pumpfun create event: %s", err)
+		return nil
+	}
+	creator := event.Creator
+	if creator.IsZero() {
+		creator = event.User
+	}
+	launch := &TokenLaunch{
+		Platform:  LAUNCH_PUMPFUN,
+		ProgramID: PUMP_FUN_PROGRAM_ID,
+		Mint:      event.Mint,
+		QuoteMint: NATIVE_SOL_MINT_PROGRAM_ID,
+		Pool:      event.BondingCurve,
+		Creator:   creator,
+		Name:      event.Name,
+		Symbol:    event.Symbol,
+		URI:       event.Uri,
+		Decimals:  pumpfunTokenDecimals,
+	}
+
+	buy := &InitialBuy{QuoteMint: NATIVE_SOL_MINT_PROGRAM_ID}
+	for _, trade := range p.PumpfunTradeEvents() {
+		if !trade.IsBuy || !trade.Mint.Equals(event.Mint) ||
+			!(trade.User.Equals(creator) || trade.User.Equals(event.User)) {
+			continue
+		}
+		buy.QuoteAmount += trade.SolAmount
+		buy.TokenAmount += trade.TokenAmount
+	}
+	if buy.TokenAmount > 0 {
+		launch.InitialBuy = buy
+	}
+	return launch
+}
+
+// parsePumpfunCreateEvent decodes a CreateEvent payload; Creator is only
+// present in events emitted since creator fees.
+func parsePumpfunCreateEvent(data []byte) (*PumpfunCreateEvent, error) {
+	decoder := ag_binary.NewBorshDecoder(data)
+	var base struct {
+		Name         string
+		Symbol       string
+		Uri          string
+		Mint         solana.PublicKey
+		BondingCurve solana.PublicKey
+		User         solana.PublicKey
+	}
+	if err := decoder.Decode(&base); err != nil {
+		return nil, err
+	}
+	event := &PumpfunCreateEvent{
+		Name:         base.Name,
+		Symbol:       base.Symbol,
+		Uri:          base.Uri,
+		Mint:         base.Mint,
+		BondingCurve: base.BondingCurve,
+		User:         base.User,
+	}
+	if decoder.Remaining() >= 32 {
+		if err := decoder.Decode(&event.Creator); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// LaunchLab initialize: base_mint_param {decimals, name, symbol, uri}, ...
+// Accounts: payer, creator, global_config, platform_config, authority,
+// pool_state, base_mint, quote_mint, base_vault, quote_vault, ...
+func (p *Parser) launchLabLaunch(inst solana.CompiledInstruction) *TokenLaunch {
+	disc, ok := prefix8(inst.Data)
+	if !ok || !launchLabInitializeDiscs[disc] || len(inst.Accounts) < 10 {
+		return nil
+	}
+	var params struct {
+		Decimals uint8
+		Name     string
+		Symbol   string
+		URI      string
+	}
+	if err := ag_binary.NewBorshDecoder(inst.Data[8:]).Decode(&params); err != nil {
+		p.Log.Errorf("error decoding LaunchLab initialize: %s", err)
+		return nil
+	}
+	key := func(i int) solana.PublicKey { return p.instructionAccount(inst, i) }
+	launch := &TokenLaunch{
+		Platform:  LAUNCH_RAYDIUM_LAUNCHLAB,
+		ProgramID: RAYDIUM_LAUNCHLAB_PROGRAM_ID,
+		Mint:      key(6),
+		QuoteMint: key(7),
+		Pool:      key(5),
+		Creator:   key(1),
+		Name:      params.Name,
+		Symbol:    params.Symbol,
+		URI:       params.URI,
+		Decimals:  params.Decimals,
+	}
+	launch.InitialBuy = p.curveInitialBuy(launch, key(8), key(9))
+	return launch
+}
+
+// Meteora DBC initialize_virtual_pool_*: {name, symbol, uri}.
+// Accounts: config, pool_authority, creator, base_mint, quote_mint, pool,
+// base_vault, quote_vault, ...
+func (p *Parser) meteoraDBCLaunch(inst solana.CompiledInstruction) *TokenLaunch {
+	disc, ok := prefix8(inst.Data)
+	if !ok || !meteoraDBCInitializeDiscs[disc] || len(inst.Accounts) < 8 {
+		return nil
+	}
+	var params struct {
+		Name   string
+		Symbol string
+		URI    string
+	}
+	if err := ag_binary.NewBorshDecoder(inst.Data[8:]).Decode(&params); err != nil {
+		p.Log.Errorf("error decoding Meteora DBC initialize: %s", err)
+		return nil
+	}
+	key := func(i int) solana.PublicKey { return p.instructionAccount(inst, i) }
+	launch := &TokenLaunch{
+		Platform:  LAUNCH_METEORA_DBC,
+		ProgramID: METEORA_DBC_PROGRAM_ID,
+		Mint:      key(3),
+		QuoteMint: key(4),
+		Pool:      key(5),
+		Creator:   key(2),
+		Name:      params.Name,
+		Symbol:    params.Symbol,
+		URI:       params.URI,
+		Decimals:  p.mintDecimals(key(3)),
+	}
+	launch.InitialBuy = p.curveInitialBuy(launch, key(6), key(7))
+	return launch
+}
+
+// curveInitialBuy sums the creator's quote transfers into the quote vault and
+// the base transfers out of the base vault into accounts the creator owns.
+func (p *Parser) curveInitialBuy(launch *TokenLaunch, baseVault, quoteVault solana.PublicKey) *InitialBuy {
+	buy := &InitialBuy{QuoteMint: launch.QuoteMint}
+	creator := launch.Creator.String()
+	for _, set := range p.txMeta.InnerInstructions {
+		for _, ri := range set.Instructions {
+			inst := p.convertRPCToSolanaInstruction(ri)
+			var source, destination, authority string
+			var amount uint64
+			switch {
+			case p.isTransfer(inst):
+				t := p.processTransfer(inst)
+				source, destination, authority, amount = t.Info.Source, t.Info.Destination, t.Info.Authority, t.Info.Amount
+			case p.isTransferCheck(inst):
+				t := p.processTransferCheck(inst)
+				source, destination, authority = t.Info.Source, t.Info.Destination, t.Info.Authority
+				amount, _ = readU64(inst.Data, 1)
+			default:
+				continue
+			}
+			switch {
+			case destination == quoteVault.String() && authority == creator:
+				buy.QuoteAmount += amount
+			case source == baseVault.String():
+				if owner, ok := p.tokenAccountOwner(destination); ok && owner.Equals(launch.Creator) {
+					buy.TokenAmount += amount
+				}
+			}
+		}
+	}
+	if buy.TokenAmount == 0 {
+		return nil
+	}
+	return buy
+}
@@ -0,0 +1,118 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func borshString(data []byte, s string) []byte {
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(s)))
+	return append(data, s...)
+}
+
+func TestParseLaunches_PumpfunCreateAndBuy(t *testing.T) {
+	mint, curve := testKey(1), testKey(2)
+	keys := []solana.PublicKey{testPayer, mint, curve, PUMP_FUN_PROGRAM_ID}
+
+	create := append([]byte{}, PumpfunCreateEventDiscriminator[:]...)
+	create = borshString(create, "Test Coin")
+	create = borshString(create, "TEST")
+	create = borshString(create, "https://example.com/test.json")
+	create = append(create, mint.Bytes()...)
+	create = append(create, curve.Bytes()...)
+	create = append(create, testPayer.Bytes()...)
+
+	trade := append([]byte{}, PumpfunTradeEventDiscriminator[:]...)
+	trade = append(trade, pumpfunTradeEventData(1)...)
+	copy(trade[16:48], mint.Bytes())
+
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{
+			{Index: 0, Instructions: []rpc.CompiledInstruction{{ProgramIDIndex: 3, Accounts: []uint16{3}, Data: create}}},
+			{Index: 1, Instructions: []rpc.CompiledInstruction{{ProgramIDIndex: 3, Accounts: []uint16{3}, Data: trade}}},
+		},
+	}
+	p := newTestParser(t, keys, []solana.CompiledInstruction{
+		{ProgramIDIndex: 3, Accounts: []uint16{1, 2, 0}, Data: []byte{24, 30, 200, 40, 5, 28, 7, 119}},
+		{ProgramIDIndex: 3, Accounts: []uint16{1, 2, 0}, Data: pumpBuyDisc[:]},
+	}, meta)
+	blockTime := solana.UnixTimeSeconds(1_700_000_000)
+	p.SetBlockInfo(250_000_000, &blockTime)
+
+	launches, err := p.ParseLaunches()
+	if err != nil {
+		t.Fatalf("ParseLaunches: %v", err)
+	}
+	if len(launches) != 1 {
+		t.Fatalf("got %d launches, want 1", len(launches))
+	}
+	l := launches[0]
+	if l.Platform != LAUNCH_PUMPFUN || !l.Mint.Equals(mint) || !l.Pool.Equals(curve) || !l.Creator.Equals(testPayer) {
+		t.Errorf("launch = %+v", l)
+	}
+	if l.Symbol != "TEST" || l.Decimals != 6 || l.Slot != 250_000_000 || l.InstructionIndex != 0 {
+		t.Errorf("symbol/decimals/slot/index = %s/%d/%d/%d", l.Symbol, l.Decimals, l.Slot, l.InstructionIndex)
+	}
+	if l.InitialBuy == nil || l.InitialBuy.QuoteAmount != 1_000_000_000 || l.InitialBuy.TokenAmount != 35_000_000_000 {
+		t.Errorf("initial buy = %+v", l.InitialBuy)
+	}
+}
+
+func TestParseLaunches_LaunchLabInitialize(t *testing.T) {
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	creator := testPayer
+	globalConfig, platformConfig, authority, pool, mint, quoteMint, baseVault, quoteVault, userBase, userQuote :=
+		testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), solana.MustPublicKeyFromBase58(usdc), testKey(6), testKey(7), testKey(8), testKey(9)
+	keys := []solana.PublicKey{
+		creator, globalConfig, platformConfig, authority, pool, mint, quoteMint, // 0-6
+		baseVault, quoteVault, userBase, userQuote, // 7-10
+		solana.TokenProgramID, RAYDIUM_LAUNCHLAB_PROGRAM_ID, // 11-12
+	}
+	disc := anchorDiscriminator8("initialize")
+	data := append([]byte{}, disc[:]...)
+	data = append(data, 6)
+	data = borshString(data, "Lab Coin")
+	data = borshString(data, "LAB")
+	data = borshString(data, "https://example.com/lab.json")
+
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 1,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 11, Accounts: []uint16{10, 8, 0}, Data: transferData(5_000_000)},
+				{ProgramIDIndex: 11, Accounts: []uint16{7, 9, 3}, Data: transferData(123_000_000)},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(7, mint.String(), authority, "0", 6),
+			tokenBalance(8, usdc, authority, "5000000", 6),
+			tokenBalance(9, mint.String(), creator, "123000000", 6),
+			tokenBalance(10, usdc, creator, "0", 6),
+		},
+	}
+	p := newTestParser(t, keys, []solana.CompiledInstruction{
+		{ProgramIDIndex: 12, Accounts: []uint16{0, 0, 1, 2, 3, 4, 5, 6, 7, 8}, Data: data},
+		{ProgramIDIndex: 12, Accounts: []uint16{0, 3, 1, 2, 4, 9, 10, 7, 8}, Data: anchorSwapDisc[:]},
+	}, meta)
+
+	launches, err := p.ParseLaunches()
+	if err != nil {
+		t.Fatalf("ParseLaunches: %v", err)
+	}
+	if len(launches) != 1 {
+		t.Fatalf("got %d launches, want 1", len(launches))
+	}
+	l := launches[0]
+	if l.Platform != LAUNCH_RAYDIUM_LAUNCHLAB || !l.Mint.Equals(mint) || !l.QuoteMint.Equals(quoteMint) || !l.Pool.Equals(pool) {
+		t.Errorf("launch = %+v", l)
+	}
+	if l.Name != "Lab Coin" || l.Decimals != 6 || !l.Creator.Equals(creator) {
+		t.Errorf("name/decimals/creator = %s/%d/%s", l.Name, l.Decimals, l.Creator)
+	}
+	if l.InitialBuy == nil || l.InitialBuy.QuoteAmount != 5_000_000 || l.InitialBuy.TokenAmount != 123_000_000 {
+		t.Errorf("initial buy = %+v", l.InitialBuy)
+	}
+}
@@ -0,0 +1,260 @@
+package solanaswapgo
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// LiquidityEvent is a deposit into or withdrawal from an AMM pool.
+type LiquidityEvent struct {
+	Op        LiquidityOp // LiquidityAdd or LiquidityRemove
+	ProgramID solana.PublicKey
+	Pool      solana.PublicKey
+	Provider  solana.PublicKey
+	// Instruction is the table name of the AMM instruction ("withdraw",
+	// "remove_liquidity_by_range", ...); empty when it isn't in the tables.
+	Instruction string
+
+	// TokenA/TokenB are the pool tokens deposited (add) or withdrawn (remove),
+	// in transfer order; TokenB is zero for single-sided liquidity.
+	TokenA LiquidityAmount
+	TokenB LiquidityAmount
+
+	// LPMint/LPAmount are the LP tokens minted (add) or burned (remove); zero
+	// for position-based pools (CLMM, Whirlpool, DLMM) that issue no LP token.
+	LPMint   solana.PublicKey
+	LPAmount uint64
+
+	InstructionIndex int
+	Signature        solana.Signature
+	Slot             uint64
+	Timestamp        time.Time
+}
+
+// LiquidityAmount is one token side of a LiquidityEvent.
+type LiquidityAmount struct {
+	Mint     solana.PublicKey
+	Amount   uint64
+	Decimals uint8
+}
+
+// liquidityTransfer is a token movement under a liquidity instruction.
+type liquidityTransfer struct {
+	mint      string
+	decimals  uint8
+	amount    uint64
+	authority string
+}
+
+// ParseLiquidity returns the add/remove-liquidity operations in the
+// transaction, one per outer instruction that reaches an AMM.
+func (p *Parser) ParseLiquidity() ([]LiquidityEvent, error) {
+	if p.IsFailed() {
+		return nil, nil
+	}
+	txOp := p.DetectLiquidityOp()
+	if txOp != LiquidityAdd && txOp != LiquidityRemove {
+		return nil, nil
+	}
+
+	var events []LiquidityEvent
+	for i := range p.txInfo.Message.Instructions {
+		if ev := p.liquidityEventAt(i, txOp); ev != nil {
+			ev.InstructionIndex = i
+			ev.Slot = p.slot
+			if len(p.txInfo.Signatures) > 0 {
+				ev.Signature = p.txInfo.Signatures[0]
+			}
+			if p.blockTime != nil {
+				ev.Timestamp = p.blockTime.Time()
+			}
+			events = append(events, *ev)
+		}
+	}
+	return events, nil
+}
+
+// liquidityEventAt decodes the liquidity operation under outer instruction i:
+// the LP MintTo/Burn made by the AMM instruction itself give the LP side and
+// direction, the transfers under the outer instruction the pool tokens.
+func (p *Parser) liquidityEventAt(i int, txOp LiquidityOp) *LiquidityEvent {
+	var nodes []*InstructionNode
+	p.InstructionTree()[i].Walk(func(n *InstructionNode) bool {
+		nodes = append(nodes, n)
+		return true
+	})
+
+	// The add/remove instruction from the tables, else the first AMM call the
+	// tables don't know. Known swaps and other instructions are never it: the
+	// swap of a zap (swap, then deposit) is not a second liquidity event.
+	var amm *InstructionNode
+	var name string
+	for _, n := range nodes {
+		if !p.isAMMProgram(n.ProgramID) || isAnchorEventCPI(n.Instruction.Data) {
+			continue
+		}
+		if info, ok := p.lookupInstruction(n.Instruction); ok {
+			if info.Kind == InstructionAddLiquidity || info.Kind == InstructionRemoveLiquidity {
+				amm, name = n, info.Name
+				break
+			}
+			continue
+		}
+		if amm == nil {
+			amm = n
+		}
+	}
+	if amm == nil {
+		return nil
+	}
+
+	ev := &LiquidityEvent{Op: txOp, ProgramID: amm.ProgramID, Instruction: name}
+	// Only the pool's own LP mint: vaults the pool deposits into (Meteora
+	// DAMM v1) mint and burn their LP tokens one CPI level down. With stack
+	// heights those are told apart by depth; without them every CPI of the
+	// outer instruction is a candidate and the LP mint named in the AMM
+	// instruction's accounts picks the right one.
+	lpMint, _ := p.liquidityLPMintFor(amm.Instruction)
+	candidates := amm.Children
+	if !p.hasStackHeights(i) {
+		candidates = nodes
+	}
+	for _, c := range candidates {
+		inst := c.Instruction
+		switch op, _ := p.tokenOpcodeIfAny(inst); {
+		case isTokenOp(tokenMintOps, op) && len(inst.Accounts) >= 2:
+			mint := p.instructionAccount(inst, 0)
+			if !ev.LPMint.IsZero() && !mint.Equals(ev.LPMint) || !lpMint.IsZero() && !mint.Equals(lpMint) {
+				continue
+			}
+			amount, _ := readU64(inst.Data, 1)
+			ev.Op, ev.LPMint = LiquidityAdd, mint
+			ev.LPAmount += amount
+		case isTokenOp(tokenBurnOps, op) && len(inst.Accounts) >= 3:
+			mint := p.instructionAccount(inst, 1)
+			if !ev.LPMint.IsZero() && !mint.Equals(ev.LPMint) || !lpMint.IsZero() && !mint.Equals(lpMint) {
+				continue
+			}
+			amount, _ := readU64(inst.Data, 1)
+			ev.Op, ev.LPMint = LiquidityRemove, mint
+			ev.LPAmount += amount
+			if ev.Provider.IsZero() {
+				ev.Provider = p.instructionAccount(inst, 2)
+			}
+		}
+	}
+
+	var transfers []liquidityTransfer
+	for _, n := range nodes {
+		inst := n.Instruction
+		switch {
+		case p.isTransferCheck(inst):
+			t := p.processTransferCheck(inst)
+			amount, _ := strconv.ParseUint(t.Info.TokenAmount.Amount, 10, 64)
+			transfers = append(transfers, liquidityTransfer{t.Info.Mint, t.Info.TokenAmount.Decimals, amount, t.Info.Authority})
+		case p.isTransfer(inst):
+			t := p.processTransfer(inst)
+			transfers = append(transfers, liquidityTransfer{t.Mint, t.Decimals, t.Info.Amount, t.Info.Authority})
+		}
+	}
+	if ev.LPAmount == 0 && len(transfers) == 0 {
+		return nil
+	}
+
+	if ev.Provider.IsZero() {
+		ev.Provider = p.allAccountKeys[0]
+		for _, t := range transfers {
+			if pk, err := solana.PublicKeyFromBase58(t.authority); err == nil && p.isSigner(pk) {
+				ev.Provider = pk
+				break
+			}
+		}
+	}
+
+	// Deposits are authorized by the provider; withdrawals by the pool.
+	var sides []LiquidityAmount
+	for _, t := range transfers {
+		byProvider := t.authority == ev.Provider.String()
+		if (ev.Op == LiquidityAdd) != byProvider {
+			continue
+		}
+		mint, err := solana.PublicKeyFromBase58(t.mint)
+		if err != nil || mint.Equals(ev.LPMint) {
+			continue
+		}
+		found := false
+		for k := range sides {
+			if sides[k].Mint.Equals(mint) {
+				sides[k].Amount += t.amount
+				found = true
+				break
+			}
+		}
+		if !found {
+			sides = append(sides, LiquidityAmount{Mint: mint, Amount: t.amount, Decimals: t.decimals})
+		}
+	}
+	if len(sides) > 0 {
+		ev.TokenA = sides[0]
+	}
+	if len(sides) > 1 {
+		ev.TokenB = sides[1]
+	}
+	ev.Pool, _ = p.liquidityPoolAccountFor(amm.Instruction, ev.Op)
+	return ev
+}
+
+func isTokenOp(set map[byte]struct{}, op byte) bool {
+	_, ok := set[op]
+	return ok
+}
+
+// liquidityPoolAccountFor returns the pool account of a deposit/withdraw
+// instruction; it differs from the swap layout in poolAccountFor for most AMMs.
+func (p *Parser) liquidityPoolAccountFor(inst solana.CompiledInstruction, op LiquidityOp) (solana.PublicKey, bool) {
+	progID := p.allAccountKeys[inst.ProgramIDIndex]
+	idx := -1
+	switch {
+	case progID.Equals(RAYDIUM_V4_PROGRAM_ID):
+		idx = 1 // amm
+	case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID):
+		idx = 2 // pool_state
+	case progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
+		idx = 2 // pool_state (increase_liquidity)
+		if op == LiquidityRemove {
+			idx = 3 // decrease_liquidity
+		}
+	case progID.Equals(ORCA_PROGRAM_ID),
+		progID.Equals(METEORA_POOLS_PROGRAM_ID),
+		progID.Equals(METEORA_DAMM_V2_PROGRAM_ID),
+		progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
+		idx = 0 // whirlpool / pool
+	case progID.Equals(METEORA_PROGRAM_ID), progID.Equals(METEORA_DLMM_PROGRAM_ID):
+		idx = 1 // lb_pair
+	}
+	if idx < 0 || idx >= len(inst.Accounts) || int(inst.Accounts[idx]) >= len(p.allAccountKeys) {
+		return solana.PublicKey{}, false
+	}
+	return p.allAccountKeys[inst.Accounts[idx]], true
+}
+
+// liquidityLPMintFor returns the LP mint account of a deposit/withdraw
+// instruction, for the AMMs that issue an LP token.
+func (p *Parser) liquidityLPMintFor(inst solana.CompiledInstruction) (solana.PublicKey, bool) {
+	progID := p.allAccountKeys[inst.ProgramIDIndex]
+	idx := -1
+	switch {
+	case progID.Equals(RAYDIUM_V4_PROGRAM_ID), progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
+		idx = 5 // lp_mint
+	case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID):
+		idx = 12 // lp_mint
+	case progID.Equals(METEORA_POOLS_PROGRAM_ID):
+		idx = 1 // lp_mint
+	}
+	if idx < 0 || idx >= len(inst.Accounts) || int(inst.Accounts[idx]) >= len(p.allAccountKeys) {
+		return solana.PublicKey{}, false
+	}
+	return p.allAccountKeys[inst.Accounts[idx]], true
+}
@@ -0,0 +1,252 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// newRaydiumV4WithdrawParser builds a Raydium V4 withdraw: testPayer burns
+// 500 LP and receives SOL and BONK from the vaults.
+func newRaydiumV4WithdrawParser(t *testing.T) (p *Parser, amm, lpMint solana.PublicKey) {
+	t.Helper()
+	userSOL, userBonk, vaultSOL, vaultBonk, ammAuthority, userLP := testKey(1), testKey(2), testKey(3), testKey(4), testKey(6), testKey(10)
+	amm, lpMint = testKey(5), testKey(11)
+	keys := []solana.PublicKey{
+		testPayer, userSOL, userBonk, vaultSOL, vaultBonk, amm, ammAuthority, // 0-6
+		solana.TokenProgramID, RAYDIUM_V4_PROGRAM_ID, userLP, lpMint, // 7-10
+	}
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	burn := binary.LittleEndian.AppendUint64([]byte{8}, 500)
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 7, Accounts: []uint16{9, 10, 0}, Data: burn},
+				{ProgramIDIndex: 7, Accounts: []uint16{3, 1, 6}, Data: transferData(2_000_000)},
+				{ProgramIDIndex: 7, Accounts: []uint16{4, 2, 6}, Data: transferData(84_000)},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(1, sol, testPayer, "2000000", 9),
+			tokenBalance(2, testBonkMint, testPayer, "84000", 5),
+			tokenBalance(3, sol, ammAuthority, "0", 9),
+			tokenBalance(4, testBonkMint, ammAuthority, "0", 5),
+		},
+	}
+	p = newTestParser(t, keys,
+		// token program, amm, authority, open orders/target orders (the authority
+		// stands in), lp mint, vaults, then the user's LP, SOL, BONK and owner
+		[]solana.CompiledInstruction{{ProgramIDIndex: 8, Accounts: []uint16{7, 5, 6, 6, 6, 10, 3, 4, 9, 1, 2, 0}, Data: binary.LittleEndian.AppendUint64([]byte{4}, 500)}},
+		meta,
+	)
+	return p, amm, lpMint
+}
+
+func TestParseLiquidity_RaydiumV4Withdraw(t *testing.T) {
+	p, amm, lpMint := newRaydiumV4WithdrawParser(t)
+
+	events, err := p.ParseLiquidity()
+	if err != nil {
+		t.Fatalf("ParseLiquidity: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.Op != LiquidityRemove || !ev.ProgramID.Equals(RAYDIUM_V4_PROGRAM_ID) || !ev.Pool.Equals(amm) || !ev.Provider.Equals(testPayer) {
+		t.Errorf("event = %+v", ev)
+	}
+	if ev.Instruction != "withdraw" {
+		t.Errorf("Instruction = %q, want withdraw", ev.Instruction)
+	}
+	if !ev.LPMint.Equals(lpMint) || ev.LPAmount != 500 {
+		t.Errorf("LP = %s/%d", ev.LPMint, ev.LPAmount)
+	}
+	if !ev.TokenA.Mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || ev.TokenA.Amount != 2_000_000 || ev.TokenA.Decimals != 9 {
+		t.Errorf("TokenA = %+v", ev.TokenA)
+	}
+	if ev.TokenB.Mint.String() != testBonkMint || ev.TokenB.Amount != 84_000 {
+		t.Errorf("TokenB = %+v", ev.TokenB)
+	}
+}
+
+func TestParseTransaction_IncludeLiquidityTxs(t *testing.T) {
+	p, _, _ := newRaydiumV4WithdrawParser(t)
+	if swaps, err := p.ParseTransaction(); err != nil || swaps != nil {
+		t.Fatalf("default ParseTransaction = %v, %v; want nil", swaps, err)
+	}
+
+	p.IncludeLiquidityTxs = true
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	if len(swaps) == 0 {
+		t.Error("IncludeLiquidityTxs: got no legs")
+	}
+}
+
+func TestParseLiquidity_MeteoraPoolsRemoveBalance(t *testing.T) {
+	pool, lpMint, userLP := testKey(20), testKey(21), testKey(22)
+	aVaultLP, bVaultLP, aVault, bVault, aVaultLPMint, bVaultLPMint := testKey(23), testKey(24), testKey(25), testKey(26), testKey(27), testKey(28)
+	aTokenVault, bTokenVault, userA, userB, vaultProgram := testKey(29), testKey(30), testKey(31), testKey(32), testKey(33)
+	keys := []solana.PublicKey{
+		testPayer, pool, lpMint, userLP, aVaultLP, bVaultLP, aVault, bVault, // 0-7
+		aVaultLPMint, bVaultLPMint, aTokenVault, bTokenVault, userA, userB, // 8-13
+		vaultProgram, solana.TokenProgramID, METEORA_POOLS_PROGRAM_ID, // 14-16
+	}
+	disc := anchorDiscriminator8("remove_balance_liquidity")
+	data := binary.LittleEndian.AppendUint64(disc[:], 1_000)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	burn := func(amount uint64) []byte { return binary.LittleEndian.AppendUint64([]byte{8}, amount) }
+
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 15, Accounts: []uint16{3, 2, 0}, Data: burn(1_000), StackHeight: 2},
+				// each vault withdraw burns the pool's vault LP and pays the user
+				{ProgramIDIndex: 14, Accounts: []uint16{6, 10, 8, 4, 12, 1}, Data: []byte{1}, StackHeight: 2},
+				{ProgramIDIndex: 15, Accounts: []uint16{4, 8, 1}, Data: burn(700), StackHeight: 3},
+				{ProgramIDIndex: 15, Accounts: []uint16{10, 12, 6}, Data: transferData(2_000_000), StackHeight: 3},
+				{ProgramIDIndex: 14, Accounts: []uint16{7, 11, 9, 5, 13, 1}, Data: []byte{1}, StackHeight: 2},
+				{ProgramIDIndex: 15, Accounts: []uint16{5, 9, 1}, Data: burn(300), StackHeight: 3},
+				{ProgramIDIndex: 15, Accounts: []uint16{11, 13, 7}, Data: transferData(84_000), StackHeight: 3},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(10, sol, aVault, "0", 9),
+			tokenBalance(11, testBonkMint, bVault, "0", 5),
+			tokenBalance(12, sol, testPayer, "2000000", 9),
+			tokenBalance(13, testBonkMint, testPayer, "84000", 5),
+		},
+	}
+	p := newTestParser(t, keys,
+		[]solana.CompiledInstruction{{ProgramIDIndex: 16, Accounts: []uint16{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 0, 14, 15}, Data: data}},
+		meta,
+	)
+
+	events, err := p.ParseLiquidity()
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ParseLiquidity = %+v, %v; want one event", events, err)
+	}
+	ev := events[0]
+	if ev.Op != LiquidityRemove || ev.Instruction != "remove_balance_liquidity" || !ev.Pool.Equals(pool) || !ev.Provider.Equals(testPayer) {
+		t.Errorf("event = %+v", ev)
+	}
+	if !ev.LPMint.Equals(lpMint) || ev.LPAmount != 1_000 {
+		t.Errorf("LP = %s/%d, want %s/1000", ev.LPMint, ev.LPAmount, lpMint)
+	}
+	if !ev.TokenA.Mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || ev.TokenA.Amount != 2_000_000 || ev.TokenB.Mint.String() != testBonkMint || ev.TokenB.Amount != 84_000 {
+		t.Errorf("tokens = %+v / %+v", ev.TokenA, ev.TokenB)
+	}
+}
+
+func TestParseLiquidity_ZapSkipsSwap(t *testing.T) {
+	userSOL, userBonk, vaultSOL, vaultBonk, amm, ammAuthority, userLP, lpMint := testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), testKey(6), testKey(10), testKey(11)
+	keys := []solana.PublicKey{
+		testPayer, userSOL, userBonk, vaultSOL, vaultBonk, amm, ammAuthority, // 0-6
+		solana.TokenProgramID, RAYDIUM_V4_PROGRAM_ID, userLP, lpMint, // 7-10
+	}
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{
+			{Index: 0, Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 7, Accounts: []uint16{1, 3, 0}, Data: transferData(1_000_000)},
+				{ProgramIDIndex: 7, Accounts: []uint16{4, 2, 6}, Data: transferData(42_000)},
+			}},
+			{Index: 1, Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 7, Accounts: []uint16{1, 3, 0}, Data: transferData(500_000)},
+				{ProgramIDIndex: 7, Accounts: []uint16{2, 4, 0}, Data: transferData(21_000)},
+				{ProgramIDIndex: 7, Accounts: []uint16{10, 9, 6}, Data: binary.LittleEndian.AppendUint64([]byte{7}, 300)},
+			}},
+		},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(1, sol, testPayer, "0", 9),
+			tokenBalance(2, testBonkMint, testPayer, "21000", 5),
+			tokenBalance(3, sol, ammAuthority, "1500000", 9),
+			tokenBalance(4, testBonkMint, ammAuthority, "979000", 5),
+		},
+	}
+	p := newTestParser(t, keys, []solana.CompiledInstruction{
+		{ProgramIDIndex: 8, Accounts: []uint16{7, 5, 6, 3, 4, 1, 2, 0}, Data: []byte{9}},
+		{ProgramIDIndex: 8, Accounts: []uint16{7, 5, 6, 6, 6, 10, 3, 4, 9, 1, 2, 0}, Data: []byte{3}},
+	}, meta)
+
+	events, err := p.ParseLiquidity()
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ParseLiquidity = %+v, %v; want the deposit only", events, err)
+	}
+	ev := events[0]
+	if ev.InstructionIndex != 1 || ev.Op != LiquidityAdd || ev.Instruction != "deposit" || !ev.LPMint.Equals(lpMint) || ev.LPAmount != 300 {
+		t.Errorf("event = %+v", ev)
+	}
+	if ev.TokenA.Amount != 500_000 || ev.TokenB.Amount != 21_000 {
+		t.Errorf("tokens = %+v / %+v", ev.TokenA, ev.TokenB)
+	}
+}
+
+func TestParseLiquidity_RoutedWithoutStackHeights(t *testing.T) {
+	pool, lpMint, userLP := testKey(20), testKey(21), testKey(22)
+	aVaultLP, bVaultLP, aVault, bVault, aVaultLPMint, bVaultLPMint := testKey(23), testKey(24), testKey(25), testKey(26), testKey(27), testKey(28)
+	aTokenVault, bTokenVault, userA, userB, vaultProgram, router := testKey(29), testKey(30), testKey(31), testKey(32), testKey(33), testKey(34)
+	keys := []solana.PublicKey{
+		testPayer, pool, lpMint, userLP, aVaultLP, bVaultLP, aVault, bVault, // 0-7
+		aVaultLPMint, bVaultLPMint, aTokenVault, bTokenVault, userA, userB, // 8-13
+		vaultProgram, solana.TokenProgramID, METEORA_POOLS_PROGRAM_ID, router, // 14-17
+	}
+	disc := anchorDiscriminator8("remove_balance_liquidity")
+	data := binary.LittleEndian.AppendUint64(disc[:], 1_000)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	burn := func(amount uint64) []byte { return binary.LittleEndian.AppendUint64([]byte{8}, amount) }
+	ammAccounts := []uint16{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 0, 14, 15}
+
+	// An older node: no stack heights, and the vault LP burns come first.
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 16, Accounts: ammAccounts, Data: data},
+				{ProgramIDIndex: 14, Accounts: []uint16{6, 10, 8, 4, 12, 1}, Data: []byte{1}},
+				{ProgramIDIndex: 15, Accounts: []uint16{4, 8, 1}, Data: burn(700)},
+				{ProgramIDIndex: 15, Accounts: []uint16{10, 12, 6}, Data: transferData(2_000_000)},
+				{ProgramIDIndex: 14, Accounts: []uint16{7, 11, 9, 5, 13, 1}, Data: []byte{1}},
+				{ProgramIDIndex: 15, Accounts: []uint16{5, 9, 1}, Data: burn(300)},
+				{ProgramIDIndex: 15, Accounts: []uint16{11, 13, 7}, Data: transferData(84_000)},
+				{ProgramIDIndex: 15, Accounts: []uint16{3, 2, 0}, Data: burn(1_000)},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(10, sol, aVault, "0", 9),
+			tokenBalance(11, testBonkMint, bVault, "0", 5),
+			tokenBalance(12, sol, testPayer, "2000000", 9),
+			tokenBalance(13, testBonkMint, testPayer, "84000", 5),
+		},
+	}
+	p := newTestParser(t, keys,
+		[]solana.CompiledInstruction{{ProgramIDIndex: 17, Accounts: append([]uint16{16}, ammAccounts...), Data: []byte{1}}},
+		meta,
+	)
+
+	events, err := p.ParseLiquidity()
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ParseLiquidity = %+v, %v; want one event", events, err)
+	}
+	ev := events[0]
+	if ev.Op != LiquidityRemove || !ev.Pool.Equals(pool) || !ev.Provider.Equals(testPayer) {
+		t.Errorf("event = %+v", ev)
+	}
+	if !ev.LPMint.Equals(lpMint) || ev.LPAmount != 1_000 {
+		t.Errorf("LP = %s/%d, want %s/1000", ev.LPMint, ev.LPAmount, lpMint)
+	}
+	if ev.TokenA.Amount != 2_000_000 || ev.TokenB.Amount != 84_000 {
+		t.Errorf("tokens = %+v / %+v", ev.TokenA, ev.TokenB)
+	}
+}
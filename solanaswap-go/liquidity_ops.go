@@ -2,11 +2,8 @@
 package solanaswapgo
 
 import (
-	"bytes"
-	"crypto/sha256"
-
+	"github.com/P-HOW/solana-swap-decode/solanaswap-go/idl"
 	"github.com/gagliardetto/solana-go"
-	"github.com/mr-tron/base58"
 )
 
 // LiquidityOp represents add/remove-liquidity classification.
@@ -16,8 +13,27 @@ const (
 	LiquidityNone LiquidityOp = iota
 	LiquidityAdd
 	LiquidityRemove
+	// LiquidityPoolCreate is a pool initialization or launchpad migration; see
+	// ParsePoolCreations.
+	LiquidityPoolCreate
 )
 
+func (op LiquidityOp) String() string {
+	switch op {
+	case LiquidityAdd:
+		return "add"
+	case LiquidityRemove:
+		return "remove"
+	case LiquidityPoolCreate:
+		return "pool_create"
+	default:
+		return "none"
+	}
+}
+
+// MarshalText encodes the op by name in JSON output.
+func (op LiquidityOp) MarshalText() ([]byte, error) { return []byte(op.String()), nil }
+
 // ------ AMM program allowlist (same spirit as filters.ts) ------
 func (p *Parser) isAMMProgram(pk solana.PublicKey) bool {
 	switch {
@@ -92,176 +108,142 @@ func (p *Parser) hasAnyTokenOpcode(opSet map[byte]struct{}) bool {
 // ------ Anchor discriminator helpers ------
 func anchorDiscriminator8(name string) [8]byte {
 	// first 8 bytes of sha256("global:"+name)
-	sum := sha256.Sum256([]byte("global:" + name))
-	var out [8]byte
-	copy(out[:], sum[:8])
-	return out
-}
-
-var addAnchors = func() map[[8]byte]struct{} {
-	names := []string{
-		"add_liquidity_by_strategy2",
-		"add_liquidity_by_strategy",
-		"add_liquidity_with_slippage",
-		"add_liquidity",
-		"increase_liquidity",
-		"increase_liquidity_v2",
-	}
-	m := make(map[[8]byte]struct{}, len(names))
-	for _, n := range names {
-		m[anchorDiscriminator8(n)] = struct{}{}
-	}
-	return m
-}()
-
-// Expanded to catch Meteora DAMM v2 / pools variants commonly seen in the wild.
-var removeAnchors = func() map[[8]byte]struct{} {
-	names := []string{
-		"remove_liquidity",
-		"remove_liquidity_by_strategy",
-		"remove_liquidity_by_strategy2",
-		"decrease_liquidity",
-		"decrease_liquidity_v2",
-		"close_position",
-		"withdraw",
-		"withdraw_liquidity",
-		"withdraw_one",
-		"withdraw_one_token",
-		"claim_and_withdraw",
-	}
-	m := make(map[[8]byte]struct{}, len(names))
-	for _, n := range names {
-		m[anchorDiscriminator8(n)] = struct{}{}
-	}
-	return m
-}()
-
-func (p *Parser) instDataPrefix8(inst solana.CompiledInstruction) (prefix [8]byte, ok bool) {
-	enc := inst.Data.String()
-	if len(enc) == 0 {
-		return prefix, false
-	}
-	raw, err := base58.Decode(enc)
-	if err != nil || len(raw) < 8 {
-		return prefix, false
-	}
-	copy(prefix[:], raw[:8])
-	return prefix, true
+	return idl.InstructionDiscriminator(name)
 }
 
 // ------ Scan helpers (outer + inner) ------
 func (p *Parser) anyAMMProgramPresent() bool {
-	// outer
-	for _, ix := range p.txInfo.Message.Instructions {
-		if p.isAMMProgram(p.allAccountKeys[ix.ProgramIDIndex]) {
-			return true
-		}
-	}
-	// inner
-	for _, inner := range p.txMeta.InnerInstructions {
-		for _, ri := range inner.Instructions {
-			ix := p.convertRPCToSolanaInstruction(ri)
-			if p.isAMMProgram(p.allAccountKeys[ix.ProgramIDIndex]) {
-				return true
-			}
-		}
-	}
-	return false
+	_, ok := p.firstAMMProgram()
+	return ok
 }
 
-func (p *Parser) hasAnchorPrefix(prefixes map[[8]byte]struct{}, ammOnly bool) bool {
+func (p *Parser) firstAMMProgram() (solana.PublicKey, bool) {
 	// outer
 	for _, ix := range p.txInfo.Message.Instructions {
-		if ammOnly && !p.isAMMProgram(p.allAccountKeys[ix.ProgramIDIndex]) {
-			continue
-		}
-		if pre, ok := p.instDataPrefix8(ix); ok {
-			if _, hit := prefixes[pre]; hit {
-				return true
-			}
+		if pk := p.allAccountKeys[ix.ProgramIDIndex]; p.isAMMProgram(pk) {
+			return pk, true
 		}
 	}
 	// inner
 	for _, inner := range p.txMeta.InnerInstructions {
 		for _, ri := range inner.Instructions {
 			ix := p.convertRPCToSolanaInstruction(ri)
-			if ammOnly && !p.isAMMProgram(p.allAccountKeys[ix.ProgramIDIndex]) {
-				continue
-			}
-			if pre, ok := p.instDataPrefix8(ix); ok {
-				if _, hit := prefixes[pre]; hit {
-					return true
-				}
+			if pk := p.allAccountKeys[ix.ProgramIDIndex]; p.isAMMProgram(pk) {
+				return pk, true
 			}
 		}
 	}
-	return false
+	return solana.PublicKey{}, false
 }
 
-// Meteora-specific: broader weak remove-signal fallback (DLMM + DAMM v2 + DBC + Pools + main)
-func (p *Parser) hasMeteoraRemoveContext() bool {
-	// outer
-	for _, ix := range p.txInfo.Message.Instructions {
-		pid := p.allAccountKeys[ix.ProgramIDIndex]
-		if pid.Equals(METEORA_DLMM_PROGRAM_ID) ||
-			pid.Equals(METEORA_DAMM_V2_PROGRAM_ID) ||
-			pid.Equals(METEORA_DBC_PROGRAM_ID) ||
-			pid.Equals(METEORA_POOLS_PROGRAM_ID) ||
-			pid.Equals(METEORA_PROGRAM_ID) {
-			return true
-		}
-	}
-	// inner
-	for _, inner := range p.txMeta.InnerInstructions {
-		for _, ri := range inner.Instructions {
-			ix := p.convertRPCToSolanaInstruction(ri)
-			pid := p.allAccountKeys[ix.ProgramIDIndex]
-			if pid.Equals(METEORA_DLMM_PROGRAM_ID) ||
-				pid.Equals(METEORA_DAMM_V2_PROGRAM_ID) ||
-				pid.Equals(METEORA_DBC_PROGRAM_ID) ||
-				pid.Equals(METEORA_POOLS_PROGRAM_ID) ||
-				pid.Equals(METEORA_PROGRAM_ID) {
-				return true
-			}
-		}
-	}
-	return false
+// LiquidityMatch is a DetectLiquidityOp verdict with the instruction that
+// decided it. Instruction is empty when the op came from LP MintTo/Burn on an
+// AMM instruction missing from the tables.
+type LiquidityMatch struct {
+	Op          LiquidityOp
+	ProgramID   solana.PublicKey
+	Instruction string
 }
 
 // ------ Public detection ------
-func (p *Parser) DetectLiquidityOp() LiquidityOp {
+
+// MatchLiquidityOp classifies the transaction from the per-program instruction
+// tables: any pool creation wins, then the first add/remove instruction; swaps
+// and other known instructions are LiquidityNone. Only when no AMM instruction
+// is in the tables do LP mints (add) and burns (remove) decide.
+func (p *Parser) MatchLiquidityOp() LiquidityMatch {
 	// 1) Must see an AMM program
 	if !p.anyAMMProgramPresent() {
-		return LiquidityNone
+		return LiquidityMatch{}
 	}
 
-	// 2) Hard rules: burn → remove; mint → add
-	if p.hasAnyTokenOpcode(tokenBurnOps) {
-		return LiquidityRemove
+	// 2) Table lookup on every instruction, outer then inner, in execution order
+	var first, create *LiquidityMatch
+	matched := false
+	for i, outer := range p.txInfo.Message.Instructions {
+		for _, inst := range append([]solana.CompiledInstruction{outer}, p.getInnerInstructions(i)...) {
+			info, ok := p.lookupInstruction(inst)
+			if !ok {
+				continue
+			}
+			matched = true
+			m := &LiquidityMatch{ProgramID: p.allAccountKeys[inst.ProgramIDIndex], Instruction: info.Name}
+			switch info.Kind {
+			case InstructionCreatePool:
+				m.Op = LiquidityPoolCreate
+				if create == nil {
+					create = m
+				}
+			case InstructionAddLiquidity, InstructionRemoveLiquidity:
+				m.Op = LiquidityAdd
+				if info.Kind == InstructionRemoveLiquidity {
+					m.Op = LiquidityRemove
+				}
+				if first == nil {
+					first = m
+				}
+			}
+		}
 	}
-	if p.hasAnyTokenOpcode(tokenMintOps) {
-		return LiquidityAdd
+	switch {
+	case create != nil:
+		return *create
+	case first != nil:
+		return *first
+	case matched:
+		return LiquidityMatch{}
 	}
 
-	// 3) Anchor discriminators on AMM instructions
-	if p.hasAnchorPrefix(addAnchors, true) {
-		return LiquidityAdd
+	// 3) Unknown AMM instructions: burn → remove; mint → add
+	amm, _ := p.firstAMMProgram()
+	if p.hasAnyTokenOpcode(tokenBurnOps) {
+		return LiquidityMatch{Op: LiquidityRemove, ProgramID: amm}
 	}
-	if p.hasAnchorPrefix(removeAnchors, true) {
-		return LiquidityRemove
+	if p.hasAnyTokenOpcode(tokenMintOps) {
+		return LiquidityMatch{Op: LiquidityAdd, ProgramID: amm}
 	}
+	return LiquidityMatch{}
+}
 
-	// 4) Fallback: any Meteora family signal → treat as remove (parity with JS fallback)
-	if p.hasMeteoraRemoveContext() {
-		return LiquidityRemove
+// hasSwapInstruction reports whether any instruction, outer or inner, is a
+// swap in the instruction tables.
+func (p *Parser) hasSwapInstruction() bool {
+	for i, outer := range p.txInfo.Message.Instructions {
+		for _, inst := range append([]solana.CompiledInstruction{outer}, p.getInnerInstructions(i)...) {
+			if info, ok := p.lookupInstruction(inst); ok && info.Kind == InstructionSwap {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	return LiquidityNone
+// poolCreationInstructions returns the outer instructions that create a pool,
+// directly or through a CPI (launchpad migrations), and make no swap.
+func (p *Parser) poolCreationInstructions() map[int]bool {
+	creates := make(map[int]bool)
+	for i, outer := range p.txInfo.Message.Instructions {
+		create := false
+		for _, inst := range append([]solana.CompiledInstruction{outer}, p.getInnerInstructions(i)...) {
+			info, ok := p.lookupInstruction(inst)
+			if !ok {
+				continue
+			}
+			if info.Kind == InstructionSwap {
+				create = false
+				break
+			}
+			create = create || info.Kind == InstructionCreatePool
+		}
+		if create {
+			creates[i] = true
+		}
+	}
+	return creates
 }
 
+func (p *Parser) DetectLiquidityOp() LiquidityOp { return p.MatchLiquidityOp().Op }
+
 // Convenience predicates.
 func (p *Parser) IsAddLiquidityTx() bool    { return p.DetectLiquidityOp() == LiquidityAdd }
 func (p *Parser) IsRemoveLiquidityTx() bool { return p.DetectLiquidityOp() == LiquidityRemove }
-
-// For completeness (parity with TS): quick byte-prefix compare utility.
-func bytesEq8(a [8]byte, b [8]byte) bool { return bytes.Equal(a[:], b[:]) }
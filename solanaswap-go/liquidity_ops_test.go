@@ -0,0 +1,44 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestMatchLiquidityOp_Tables(t *testing.T) {
+	disc := func(name string) solana.Base58 {
+		d := anchorDiscriminator8(name)
+		return d[:]
+	}
+	tests := []struct {
+		name     string
+		program  solana.PublicKey
+		data     solana.Base58
+		want     LiquidityOp
+		wantName string
+	}{
+		{"meteora dlmm swap", METEORA_PROGRAM_ID, disc("swap2"), LiquidityNone, ""},
+		{"meteora dlmm unknown", METEORA_PROGRAM_ID, disc("set_activation_point"), LiquidityNone, ""},
+		{"meteora dlmm remove", METEORA_PROGRAM_ID, disc("remove_liquidity_by_range2"), LiquidityRemove, "remove_liquidity_by_range2"},
+		{"pumpswap withdraw", PUMPFUN_AMM_PROGRAM_ID, disc("withdraw"), LiquidityRemove, "withdraw"},
+		{"withdraw is not an orca instruction", ORCA_PROGRAM_ID, disc("withdraw"), LiquidityNone, ""},
+		{"orca increase", ORCA_PROGRAM_ID, disc("increase_liquidity_v2"), LiquidityAdd, "increase_liquidity_v2"},
+		{"raydium v4 deposit", RAYDIUM_V4_PROGRAM_ID, []byte{3, 0, 0, 0, 0, 0, 0, 0, 0}, LiquidityAdd, "deposit"},
+		{"raydium v4 swap", RAYDIUM_V4_PROGRAM_ID, []byte{9}, LiquidityNone, ""},
+		{"cpmm initialize", RAYDIUM_CPMM_PROGRAM_ID, disc("initialize"), LiquidityPoolCreate, "initialize"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestParser(t, []solana.PublicKey{testPayer, tt.program},
+				[]solana.CompiledInstruction{{ProgramIDIndex: 1, Data: tt.data}}, nil)
+			m := p.MatchLiquidityOp()
+			if m.Op != tt.want || m.Instruction != tt.wantName {
+				t.Fatalf("MatchLiquidityOp = %s/%q, want %s/%q", m.Op, m.Instruction, tt.want, tt.wantName)
+			}
+			if m.Op != LiquidityNone && !m.ProgramID.Equals(tt.program) {
+				t.Errorf("ProgramID = %s, want %s", m.ProgramID, tt.program)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package solanaswapgo
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Method names the resolution path that produced a SwapInfo.
+type Method string
+
+const (
+	MethodJupiterEvent         Method = "jupiter_event"         // Jupiter route events
+	MethodOKXLogs              Method = "okx_logs"              // OKX router totals from program logs
+	MethodPumpfunEvent         Method = "pumpfun_event"         // Pump.fun TradeEvent
+	MethodPumpfunDiscriminator Method = "pumpfun_discriminator" // Pump.fun buy/sell discriminator + transfers
+	MethodPumpSwapEvent        Method = "pumpswap_event"        // PumpSwap Buy/SellEvent
+	MethodLamportDeltaSell     Method = "lamport_delta_sell"    // legs, SOL forced out by the trader's lamport gain
+	MethodSignerOutflow        Method = "signer_outflow"        // legs, SOL the trader sent in
+	MethodFirstLastMint        Method = "first_last_mint"       // legs, first mint seen in, last mint out
+	MethodBalanceDelta         Method = "balance_delta"         // trader balance changes, no venue decoded
+)
+
+// methodConfidence scores how much each path can be trusted: decoded events are
+// authoritative, transfer heuristics can pick the wrong leg or amount.
+var methodConfidence = map[Method]float64{
+	MethodJupiterEvent:         1,
+	MethodPumpfunEvent:         1,
+	MethodPumpSwapEvent:        1,
+	MethodOKXLogs:              0.9,
+	MethodPumpfunDiscriminator: 0.8,
+	MethodLamportDeltaSell:     0.7,
+	MethodSignerOutflow:        0.7,
+	MethodFirstLastMint:        0.4,
+	MethodBalanceDelta:         ConfidenceBalanceDelta,
+}
+
+// setMethod records the path that resolved swapInfo and its confidence.
+func (p *Parser) setMethod(swapInfo *SwapInfo, m Method) {
+	swapInfo.Method = m
+	swapInfo.Confidence = methodConfidence[m]
+	p.trace(swapInfo, "resolved by %s (confidence %.2f)", m, swapInfo.Confidence)
+}
+
+// trace appends a decision to swapInfo.Trace when the logger is at debug level.
+func (p *Parser) trace(swapInfo *SwapInfo, format string, args ...interface{}) {
+	if !p.Log.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	swapInfo.Trace = append(swapInfo.Trace, msg)
+	p.Log.Debugf("swap ix %d: %s", swapInfo.InstructionIndex, msg)
+}
@@ -29,18 +29,17 @@ var (
 	MOONSHOT_SELL_INSTRUCTION = ag_binary.TypeID([8]byte{51, 230, 133, 164, 1, 127, 131, 173})
 )
 
-// processMoonshotSwaps processes all Moonshot swap instructions in the transaction
-func (p *Parser) processMoonshotSwaps() []SwapData {
+// processMoonshotSwaps processes the Moonshot swap instruction at instructionIndex
+func (p *Parser) processMoonshotSwaps(instructionIndex int) []SwapData {
 	var swaps []SwapData
 
-	for _, instruction := range p.txInfo.Message.Instructions {
-		if p.isMoonshotTrade(instruction) {
-			swapData, err := p.parseMoonshotTradeInstruction(instruction)
-			if err != nil {
-				continue
-			}
-			swaps = append(swaps, *swapData)
+	instruction := p.txInfo.Message.Instructions[instructionIndex]
+	if p.isMoonshotTrade(instruction) {
+		swapData, err := p.parseMoonshotTradeInstruction(instruction)
+		if err != nil {
+			return swaps
 		}
+		swaps = append(swaps, *swapData)
 	}
 
 	return swaps
@@ -48,7 +47,7 @@ func (p *Parser) processMoonshotSwaps() []SwapData {
 
 // isMoonshotTrade checks if the instruction is a Moonshot trade
 func (p *Parser) isMoonshotTrade(instruction solana.CompiledInstruction) bool {
-	return p.txInfo.Message.AccountKeys[instruction.ProgramIDIndex].Equals(MOONSHOT_PROGRAM_ID) && len(instruction.Data) == 33 && len(instruction.Accounts) == 11
+	return int(instruction.ProgramIDIndex) < len(p.allAccountKeys) && p.allAccountKeys[instruction.ProgramIDIndex].Equals(MOONSHOT_PROGRAM_ID) && len(instruction.Data) == 33 && len(instruction.Accounts) == 11
 }
 
 // parseMoonshotTradeInstruction parses a Moonshot trade instruction
@@ -70,7 +69,10 @@ func (p *Parser) parseMoonshotTradeInstruction(instruction solana.CompiledInstru
 		return nil, fmt.Errorf("unknown moonshot trade instruction")
 	}
 
-	moonshotTokenMint := p.txInfo.Message.AccountKeys[instruction.Accounts[6]]
+	if int(instruction.Accounts[6]) >= len(p.allAccountKeys) {
+		return nil, fmt.Errorf("moonshot mint account is in an unresolved lookup table")
+	}
+	moonshotTokenMint := p.allAccountKeys[instruction.Accounts[6]]
 
 	moonshotTokenBalanceChanges, err := p.getTokenBalanceChanges(moonshotTokenMint)
 	if err != nil {
@@ -95,25 +97,23 @@ func (p *Parser) parseMoonshotTradeInstruction(instruction solana.CompiledInstru
 	}, nil
 }
 
-// getTokenBalanceChanges calculates the balance change for a given token mint for the signer
+// getTokenBalanceChanges calculates the balance change for a given token mint for the trader
 func (p *Parser) getTokenBalanceChanges(mint solana.PublicKey) (int64, error) {
+	trader := p.resolveTrader()
+
 	if mint == NATIVE_SOL_MINT_PROGRAM_ID {
-		// For native SOL, we'll use the first account (index 0) which is typically the fee payer/signer
-		if len(p.txMeta.PostBalances) == 0 || len(p.txMeta.PreBalances) == 0 {
+		change, ok := p.lamportDeltaFor(trader)
+		if !ok {
 			return 0, fmt.Errorf("insufficient balance information for SOL")
 		}
-		change := int64(p.txMeta.PostBalances[0]) - int64(p.txMeta.PreBalances[0])
 		return change, nil
 	}
 
-	// Get the signer's public key (assuming it's the first account in the transaction)
-	signer := p.txInfo.Message.AccountKeys[0]
-
 	var preAmount, postAmount int64
 	var balanceFound bool
 
 	for _, preBalance := range p.txMeta.PreTokenBalances {
-		if preBalance.Mint.Equals(mint) && preBalance.Owner.Equals(signer) {
+		if preBalance.Mint.Equals(mint) && preBalance.Owner.Equals(trader) {
 			preAmount, _ = strconv.ParseInt(preBalance.UiTokenAmount.Amount, 10, 64)
 			balanceFound = true
 			break
@@ -121,7 +121,7 @@ func (p *Parser) getTokenBalanceChanges(mint solana.PublicKey) (int64, error) {
 	}
 
 	for _, postBalance := range p.txMeta.PostTokenBalances {
-		if postBalance.Mint.Equals(mint) && postBalance.Owner.Equals(signer) {
+		if postBalance.Mint.Equals(mint) && postBalance.Owner.Equals(trader) {
 			postAmount, _ = strconv.ParseInt(postBalance.UiTokenAmount.Amount, 10, 64)
 			balanceFound = true
 			break
@@ -129,7 +129,7 @@ func (p *Parser) getTokenBalanceChanges(mint solana.PublicKey) (int64, error) {
 	}
 
 	if !balanceFound {
-		return 0, fmt.Errorf("could not find balance for specified mint and signer")
+		return 0, fmt.Errorf("could not find balance for specified mint and trader")
 	}
 
 	change := postAmount - preAmount
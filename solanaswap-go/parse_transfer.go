@@ -70,13 +70,13 @@ func (p *Parser) processTransfer(instr solana.CompiledInstruction) *TransferData
 	transferData := &TransferData{
 		Info: TransferInfo{
 			Amount:      amount,
-			Source:      p.allAccountKeys[instr.Accounts[0]].String(),
-			Destination: p.allAccountKeys[instr.Accounts[1]].String(),
-			Authority:   p.allAccountKeys[instr.Accounts[2]].String(),
+			Source:      p.instructionAccount(instr, 0).String(),
+			Destination: p.instructionAccount(instr, 1).String(),
+			Authority:   p.instructionAccount(instr, 2).String(),
 		},
 		Type:     "transfer",
-		Mint:     p.splTokenInfoMap[p.allAccountKeys[instr.Accounts[1]].String()].Mint,
-		Decimals: p.splTokenInfoMap[p.allAccountKeys[instr.Accounts[1]].String()].Decimals,
+		Mint:     p.splTokenInfoMap[p.instructionAccount(instr, 1).String()].Mint,
+		Decimals: p.splTokenInfoMap[p.instructionAccount(instr, 1).String()].Decimals,
 	}
 
 	if transferData.Mint == "" {
@@ -111,6 +111,10 @@ func (p *Parser) extractSPLTokenInfo() error {
 		if len(instr.Accounts) < 3 {
 			return
 		}
+		// accounts loaded from lookup tables that aren't resolved yet
+		if int(instr.Accounts[0]) >= len(p.allAccountKeys) || int(instr.Accounts[1]) >= len(p.allAccountKeys) {
+			return
+		}
 
 		source := p.allAccountKeys[instr.Accounts[0]].String()
 		destination := p.allAccountKeys[instr.Accounts[1]].String()
@@ -63,25 +63,7 @@ func (p *Parser) processMeteoraSwaps(instructionIndex int) []SwapData {
 
 // Defensive collector used as a last resort under Meteora outer instruction.
 func (p *Parser) collectTokenTransfersUnder(index int) []SwapData {
-	var legs []SwapData
-	for _, innerSet := range p.txMeta.InnerInstructions {
-		if innerSet.Index != uint16(index) {
-			continue
-		}
-		for _, rpcInst := range innerSet.Instructions {
-			inst := p.convertRPCToSolanaInstruction(rpcInst)
-			if p.isTransferCheck(inst) {
-				if tr := p.processTransferCheck(inst); tr != nil {
-					legs = append(legs, SwapData{Type: METEORA, Data: tr})
-				}
-			} else if p.isTransfer(inst) {
-				if tr := p.processTransfer(inst); tr != nil {
-					legs = append(legs, SwapData{Type: METEORA, Data: tr})
-				}
-			}
-		}
-	}
-	return legs
+	return p.TokenTransfersUnder(index, METEORA)
 }
 
 func (p *Parser) processTransferCheck(instr solana.CompiledInstruction) *TransferCheck {
@@ -91,10 +73,10 @@ func (p *Parser) processTransferCheck(instr solana.CompiledInstruction) *Transfe
 		Type: "transferChecked",
 	}
 
-	transferData.Info.Source = p.allAccountKeys[instr.Accounts[0]].String()
-	transferData.Info.Destination = p.allAccountKeys[instr.Accounts[2]].String()
-	transferData.Info.Mint = p.allAccountKeys[instr.Accounts[1]].String()
-	transferData.Info.Authority = p.allAccountKeys[instr.Accounts[3]].String()
+	transferData.Info.Source = p.instructionAccount(instr, 0).String()
+	transferData.Info.Destination = p.instructionAccount(instr, 2).String()
+	transferData.Info.Mint = p.instructionAccount(instr, 1).String()
+	transferData.Info.Authority = p.instructionAccount(instr, 3).String()
 
 	transferData.Info.TokenAmount.Amount = fmt.Sprintf("%d", amount)
 	transferData.Info.TokenAmount.Decimals = p.splDecimalsMap[transferData.Info.Mint]
@@ -6,16 +6,22 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/P-HOW/solana-swap-decode/solanaswap-go/idl"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	PROTOCOL_RAYDIUM = "raydium"
-	PROTOCOL_ORCA    = "orca"
-	PROTOCOL_METEORA = "meteora"
-	PROTOCOL_PUMPFUN = "pumpfun"
+	PROTOCOL_RAYDIUM  = "raydium"
+	PROTOCOL_ORCA     = "orca"
+	PROTOCOL_METEORA  = "meteora"
+	PROTOCOL_PUMPFUN  = "pumpfun"
+	PROTOCOL_JUPITER  = "jupiter"
+	PROTOCOL_OKX      = "okx"
+	PROTOCOL_MOONSHOT = "moonshot"
+	PROTOCOL_PHOENIX  = "phoenix"
+	PROTOCOL_BOTS     = "bots"
 )
 
 type TokenTransfer struct {
@@ -25,11 +31,27 @@ type TokenTransfer struct {
 }
 
 type Parser struct {
+	// BalanceDeltaFallback makes ParseTransaction rebuild a swap from the
+	// trader's balance changes when no registered decoder matched (opt-in).
+	BalanceDeltaFallback bool
+	// IncludeLiquidityTxs makes ParseTransaction decode add/remove-liquidity
+	// transactions instead of returning nil for them (opt-in); the deposit or
+	// withdrawal transfers may then surface as swap legs. Use ParseLiquidity
+	// for the liquidity operation itself.
+	IncludeLiquidityTxs bool
+
 	txMeta          *rpc.TransactionMeta
 	txInfo          *solana.Transaction
 	allAccountKeys  solana.PublicKeySlice
 	splTokenInfoMap map[string]TokenInfo
 	splDecimalsMap  map[string]uint8
+	tokenOwnerMap   map[string]solana.PublicKey
+	decoders        map[solana.PublicKey]ProtocolDecoder
+	idlPrograms     map[solana.PublicKey]*idl.Program
+	instructionTree []*InstructionNode
+	trader          *solana.PublicKey // resolveTrader, once computed
+	slot            uint64
+	blockTime       *solana.UnixTimeSeconds
 	Log             *logrus.Logger
 }
 
@@ -38,10 +60,29 @@ func NewTransactionParser(tx *rpc.GetTransactionResult) (*Parser, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction: %w", err)
 	}
-	return NewTransactionParserFromTransaction(txInfo, tx.Meta)
+	parser, err := NewTransactionParserFromTransaction(txInfo, tx.Meta)
+	if err != nil {
+		return nil, err
+	}
+	parser.SetBlockInfo(tx.Slot, tx.BlockTime)
+	return parser, nil
+}
+
+// SetBlockInfo records the slot and block time the transaction landed in. Parsers
+// built with NewTransactionParserFromTransaction (e.g. from getBlock) need it to
+// fill SwapInfo.Slot and SwapInfo.Timestamp.
+func (p *Parser) SetBlockInfo(slot uint64, blockTime *solana.UnixTimeSeconds) {
+	p.slot = slot
+	p.blockTime = blockTime
 }
 
+// NewTransactionParserFromTransaction builds a parser from a decoded
+// transaction and its meta. txMeta may be nil for transactions that never
+// landed (unsent, simulated); call ResolveAddressLookups for their v0 lookups.
 func NewTransactionParserFromTransaction(tx *solana.Transaction, txMeta *rpc.TransactionMeta) (*Parser, error) {
+	if txMeta == nil {
+		txMeta = &rpc.TransactionMeta{}
+	}
 	allAccountKeys := append(tx.Message.AccountKeys, txMeta.LoadedAddresses.Writable...)
 	allAccountKeys = append(allAccountKeys, txMeta.LoadedAddresses.ReadOnly...)
 
@@ -57,6 +98,9 @@ func NewTransactionParserFromTransaction(tx *solana.Transaction, txMeta *rpc.Tra
 		allAccountKeys: allAccountKeys,
 		Log:            log,
 	}
+	for _, d := range defaultDecoders() {
+		parser.RegisterDecoder(d)
+	}
 
 	if err := parser.extractSPLTokenInfo(); err != nil {
 		return nil, fmt.Errorf("failed to extract SPL Token Addresses: %w", err)
@@ -64,57 +108,61 @@ func NewTransactionParserFromTransaction(tx *solana.Transaction, txMeta *rpc.Tra
 	if err := parser.extractSPLDecimals(); err != nil {
 		return nil, fmt.Errorf("failed to extract SPL decimals: %w", err)
 	}
+	parser.extractTokenOwners()
 	return parser, nil
 }
 
 type SwapData struct {
 	Type SwapType
 	Data interface{}
+	// InstructionIndex is the outer instruction the leg was decoded under.
+	InstructionIndex int
 }
 
 // Jupiter is treated like a router only if we actually parse something under it.
 func (p *Parser) ParseTransaction() ([]SwapData, error) {
 
-	// >>> Early filter: ignore add/remove-liquidity txs entirely (before any analysis)
+	// Failed transactions moved no tokens; whatever the logs say is not a swap.
+	// Use ParseFailedSwap for the attempted intent.
+	if p.IsFailed() {
+		return nil, nil
+	}
+
+	// >>> Early filter: ignore add/remove-liquidity and pool-creation txs entirely
+	// (before any analysis); they are reported by ParseLiquidity and
+	// ParsePoolCreations. A pool created and traded in the same transaction
+	// (a snipe after initialize/create_pool) keeps its swaps; only the
+	// creating instructions, whose deposits aren't trades, are skipped.
+	var creates map[int]bool
 	switch p.DetectLiquidityOp() {
 	case LiquidityAdd, LiquidityRemove:
-		// Optional: p.Log.Debugf("liquidity tx detected; skipping")
-		return nil, nil
+		if !p.IncludeLiquidityTxs {
+			return nil, nil
+		}
+	case LiquidityPoolCreate:
+		if !p.hasSwapInstruction() {
+			return nil, nil
+		}
+		creates = p.poolCreationInstructions()
 	}
 
 	var parsedSwaps []SwapData
 
+	// First pass: routers and wrappers (Jupiter, OKX, Moonshot, sniper bots)
 	skip := false
 	for i := range p.txInfo.Message.Instructions {
 		outerInstruction := p.txInfo.Message.Instructions[i]
-		progID := p.allAccountKeys[outerInstruction.ProgramIDIndex]
-		switch {
-		case progID.Equals(JUPITER_PROGRAM_ID):
-			jup := p.processJupiterSwaps(i)
-			if len(jup) > 0 {
-				parsedSwaps = append(parsedSwaps, jup...)
-				skip = true // only skip if something was parsed under Jupiter
-			}
-		case progID.Equals(MOONSHOT_PROGRAM_ID):
-			ms := p.processMoonshotSwaps()
-			if len(ms) > 0 {
-				parsedSwaps = append(parsedSwaps, ms...)
-				skip = true
-			}
-		case progID.Equals(BANANA_GUN_PROGRAM_ID) ||
-			progID.Equals(MINTECH_PROGRAM_ID) ||
-			progID.Equals(BLOOM_PROGRAM_ID) ||
-			progID.Equals(NOVA_PROGRAM_ID) ||
-			progID.Equals(MAESTRO_PROGRAM_ID):
-			if innerSwaps := p.processRouterSwaps(i); len(innerSwaps) > 0 {
-				parsedSwaps = append(parsedSwaps, innerSwaps...)
-			}
-		case progID.Equals(OKX_DEX_ROUTER_PROGRAM_ID):
-			okx := p.processOKXSwaps(i) // includes aggregate + legs
-			if len(okx) > 0 {
-				parsedSwaps = append(parsedSwaps, okx...)
-				skip = true
-			}
+		d := p.decoderFor(p.allAccountKeys[outerInstruction.ProgramIDIndex])
+		if d == nil || d.Role() == RoleAMM || creates[i] {
+			continue
+		}
+		swaps := d.Decode(p, i)
+		if len(swaps) == 0 {
+			continue
+		}
+		parsedSwaps = append(parsedSwaps, withInstructionIndex(swaps, i)...)
+		if d.Role() == RoleRouter {
+			skip = true // only skip if something was parsed under a router
 		}
 	}
 	if skip {
@@ -124,39 +172,43 @@ func (p *Parser) ParseTransaction() ([]SwapData, error) {
 	// Fallback second pass: direct AMM outer instructions
 	for i := range p.txInfo.Message.Instructions {
 		outerInstruction := p.txInfo.Message.Instructions[i]
-		progID := p.allAccountKeys[outerInstruction.ProgramIDIndex]
-		switch {
-		case progID.Equals(RAYDIUM_V4_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CPMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_LAUNCHLAB_PROGRAM_ID) ||
-			progID.Equals(solana.MustPublicKeyFromBase58("AP51WLiiqTdbZfgyRMs35PsZpdmLuPDdHYmrB23pEtMU")):
-			parsedSwaps = append(parsedSwaps, p.processRaydSwaps(i)...)
-		case progID.Equals(ORCA_PROGRAM_ID):
-			parsedSwaps = append(parsedSwaps, p.processOrcaSwaps(i)...)
-		case progID.Equals(METEORA_PROGRAM_ID) ||
-			progID.Equals(METEORA_POOLS_PROGRAM_ID) ||
-			progID.Equals(METEORA_DLMM_PROGRAM_ID) ||
-			progID.Equals(METEORA_DBC_PROGRAM_ID) ||
-			progID.Equals(METEORA_DAMM_V2_PROGRAM_ID): // include DAMM v2
-			parsedSwaps = append(parsedSwaps, p.processMeteoraSwaps(i)...)
-		case progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
-			parsedSwaps = append(parsedSwaps, p.processPumpfunAMMSwaps(i)...)
-		case progID.Equals(PUMP_FUN_PROGRAM_ID) ||
-			progID.Equals(solana.MustPublicKeyFromBase58("BSfD6SHZigAfDWSjzD5Q41jw8LmKwtmjskPH9XW1mrRW")):
-			parsedSwaps = append(parsedSwaps, p.processPumpfunSwaps(i)...)
+		d := p.decoderFor(p.allAccountKeys[outerInstruction.ProgramIDIndex])
+		if d == nil || d.Role() != RoleAMM || creates[i] {
+			continue
+		}
+		parsedSwaps = append(parsedSwaps, withInstructionIndex(d.Decode(p, i), i)...)
+	}
+
+	if len(parsedSwaps) == 0 && p.BalanceDeltaFallback {
+		if v := p.balanceDeltaSwap(); v != nil {
+			parsedSwaps = append(parsedSwaps, SwapData{Type: UNKNOWN, Data: v, InstructionIndex: p.firstUnknownInstruction()})
 		}
 	}
 
 	return parsedSwaps, nil
 }
 
+// withInstructionIndex stamps the outer instruction index on every leg.
+func withInstructionIndex(swaps []SwapData, index int) []SwapData {
+	for i := range swaps {
+		swaps[i].InstructionIndex = index
+	}
+	return swaps
+}
+
 type SwapInfo struct {
+	// Signers holds the trader only (kept for backward compatibility).
 	Signers    []solana.PublicKey
 	Signatures []solana.Signature
 	AMMs       []string
-	Timestamp  time.Time
+	// Timestamp is the block time (zero if the RPC node didn't report one).
+	Timestamp time.Time
+	Slot      uint64
+
+	// Trader is the account whose balances moved; FeePayer paid the transaction
+	// fee. They differ for relayed/sponsored swaps.
+	Trader   solana.PublicKey
+	FeePayer solana.PublicKey
 
 	TokenInMint     solana.PublicKey
 	TokenInAmount   uint64
@@ -165,23 +217,93 @@ type SwapInfo struct {
 	TokenOutMint     solana.PublicKey
 	TokenOutAmount   uint64
 	TokenOutDecimals uint8
+
+	// Method is the resolution path that produced the swap; Confidence scores
+	// it from 0 to 1 (decoded events are 1, transfer heuristics lower).
+	Method     Method
+	Confidence float64
+	// Trace lists the decisions taken while resolving the swap; only filled
+	// when the parser's logger is at debug level.
+	Trace []string `json:",omitempty"`
+
+	// InstructionIndex is the outer instruction the swap came from
+	// (the first leg's instruction when the whole transaction is collapsed).
+	InstructionIndex int
+
+	// Hops is the route in execution order, one entry per pool touched.
+	Hops []Hop
+
+	Fees Fees
+
+	// SwapArgs is what the user declared in the swap instruction (nil when the
+	// venue's arguments aren't decoded).
+	SwapArgs *SwapArgs
+	// SlippageBps is how much worse than the declared quote the swap executed
+	// (negative: better); only set for venues whose instruction carries a quote
+	// (Jupiter routes, Moonshot).
+	SlippageBps *int64
+	// SlippageMarginBps is how far the executed amount stayed from the user's
+	// limit (minimum out, or maximum in for exact-out swaps).
+	SlippageMarginBps *int64
+
+	// PumpfunTrade is the bonding-curve TradeEvent the swap was resolved from:
+	// reserves after the trade, protocol and creator fees.
+	PumpfunTrade *PumpfunTradeEvent `json:",omitempty"`
 }
 
+// ProcessSwapData collapses every leg of the transaction into a single SwapInfo.
+// Use ProcessAllSwaps when a transaction may carry several independent swaps.
 func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 	if len(swapDatas) == 0 {
 		return nil, fmt.Errorf("no swap data provided")
 	}
+	return p.processSwapGroup(swapDatas, p.resolveTrader(), -1)
+}
+
+// processSwapGroup resolves one group of legs into a SwapInfo for the trader signer.
+// scope is the outer instruction the group belongs to, or -1 for the whole transaction.
+func (p *Parser) processSwapGroup(swapDatas []SwapData, signer solana.PublicKey, scope int) (*SwapInfo, error) {
+	swapInfo, err := p.resolveSwapGroup(swapDatas, signer, scope)
+	if err != nil {
+		return nil, err
+	}
+	p.finalizeSwapInfo(swapInfo, swapDatas, scope)
+	return swapInfo, nil
+}
+
+// finalizeSwapInfo fills the fields that don't depend on which heuristic resolved the swap.
+func (p *Parser) finalizeSwapInfo(swapInfo *SwapInfo, swapDatas []SwapData, scope int) {
+	swapInfo.Slot = p.slot
+	if p.blockTime != nil {
+		swapInfo.Timestamp = p.blockTime.Time()
+	}
+	swapInfo.Hops = p.buildHops(swapDatas)
+	swapInfo.Fees = p.buildFees(swapDatas, scope)
+	p.applySwapArgs(swapInfo)
+}
+
+func (p *Parser) resolveSwapGroup(swapDatas []SwapData, signer solana.PublicKey, scope int) (*SwapInfo, error) {
+	if len(swapDatas) == 0 {
+		return nil, fmt.Errorf("no swap data provided")
+	}
 
-	swapInfo := &SwapInfo{Signatures: p.txInfo.Signatures}
+	swapInfo := &SwapInfo{
+		Signatures:       p.txInfo.Signatures,
+		Signers:          []solana.PublicKey{signer},
+		Trader:           signer,
+		FeePayer:         p.FeePayer(),
+		InstructionIndex: swapDatas[0].InstructionIndex,
+	}
 
-	if p.containsDCAProgram() {
-		swapInfo.Signers = []solana.PublicKey{p.allAccountKeys[2]}
-	} else {
-		swapInfo.Signers = []solana.PublicKey{p.allAccountKeys[0]}
+	for _, sd := range swapDatas {
+		if v, ok := sd.Data.(*BalanceDeltaSwap); ok {
+			p.fillFromBalanceDelta(swapInfo, v)
+			return swapInfo, nil
+		}
 	}
-	signer := swapInfo.Signers[0]
 
 	// Priorities: Jupiter events → OKX aggregate → Pumpfun events/discriminators → aggregate legs
+	p.trace(swapInfo, "%d legs for trader %s", len(swapDatas), signer)
 	jupiterSwaps := make([]SwapData, 0)
 	var okxAgg *OKXSwapEventData
 	pumpfunSwaps := make([]SwapData, 0)
@@ -215,9 +337,11 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 			swapInfo.TokenOutAmount = jupiterInfo.TokenOutAmount
 			swapInfo.TokenOutDecimals = jupiterInfo.TokenOutDecimals
 			swapInfo.AMMs = jupiterInfo.AMMs
+			p.setMethod(swapInfo, MethodJupiterEvent)
 			p.adjustOrderBySolDelta(swapInfo) // final sanity (only acts when delta>0)
 			return swapInfo, nil
 		}
+		p.trace(swapInfo, "jupiter events unusable: %s", err)
 	}
 
 	// OKX aggregate (authoritative router totals)
@@ -229,7 +353,7 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 		swapInfo.TokenOutAmount = okxAgg.OutputAmount
 		swapInfo.TokenOutDecimals = okxAgg.OutputDecimals
 		swapInfo.AMMs = append(swapInfo.AMMs, string(OKX))
-		swapInfo.Timestamp = time.Now()
+		p.setMethod(swapInfo, MethodOKXLogs)
 		p.adjustOrderBySolDelta(swapInfo)
 		return swapInfo, nil
 	}
@@ -255,12 +379,41 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 				}
 				swapInfo.AMMs = append(swapInfo.AMMs, string(PUMP_FUN))
 				swapInfo.Timestamp = time.Unix(int64(data.Timestamp), 0)
+				swapInfo.PumpfunTrade = data
+				p.setMethod(swapInfo, MethodPumpfunEvent)
 				p.adjustOrderBySolDelta(swapInfo)
 				return swapInfo, nil
 			}
 		}
 
-		if has, isBuy := p.detectPumpfunBuySell(); has {
+		if len(otherSwaps) == 0 {
+			var first, last *PumpSwapTradeEvent
+			for _, sd := range pumpfunSwaps {
+				if data, ok := sd.Data.(*PumpSwapTradeEvent); ok {
+					if first == nil {
+						first = data
+					}
+					last = data
+				}
+			}
+			if first != nil {
+				// several events are a route through PumpSwap pools
+				inMint, inAmount, _, _ := first.sides()
+				_, _, outMint, outAmount := last.sides()
+				swapInfo.TokenInMint = inMint
+				swapInfo.TokenInAmount = inAmount
+				swapInfo.TokenInDecimals = p.mintDecimals(inMint)
+				swapInfo.TokenOutMint = outMint
+				swapInfo.TokenOutAmount = outAmount
+				swapInfo.TokenOutDecimals = p.mintDecimals(outMint)
+				swapInfo.AMMs = append(swapInfo.AMMs, string(PUMP_FUN))
+				p.setMethod(swapInfo, MethodPumpSwapEvent)
+				return swapInfo, nil
+			}
+		}
+
+		if has, isBuy := p.detectPumpfunBuySell(scope); has {
+			p.trace(swapInfo, "no pump.fun event; discriminator says buy=%v", isBuy)
 			solMint := NATIVE_SOL_MINT_PROGRAM_ID.String()
 
 			totalsAnyAuth := make(map[string]uint64)
@@ -269,6 +422,9 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 
 			if p.txMeta != nil && p.txMeta.InnerInstructions != nil {
 				for _, set := range p.txMeta.InnerInstructions {
+					if scope >= 0 && set.Index != uint16(scope) {
+						continue
+					}
 					for _, ri := range set.Instructions {
 						inst := p.convertRPCToSolanaInstruction(ri)
 						if !p.isTransferCheck(inst) {
@@ -319,7 +475,7 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 					swapInfo.TokenOutAmount = outAmt
 					swapInfo.TokenOutDecimals = p.splDecimalsMap[outMint]
 					swapInfo.AMMs = append(swapInfo.AMMs, string(PUMP_FUN))
-					swapInfo.Timestamp = time.Now()
+					p.setMethod(swapInfo, MethodPumpfunDiscriminator)
 					p.adjustOrderBySolDelta(swapInfo)
 					return swapInfo, nil
 				}
@@ -347,13 +503,14 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 					swapInfo.TokenOutAmount = outAmt
 					swapInfo.TokenOutDecimals = 9
 					swapInfo.AMMs = append(swapInfo.AMMs, string(PUMP_FUN))
-					swapInfo.Timestamp = time.Now()
+					p.setMethod(swapInfo, MethodPumpfunDiscriminator)
 					p.adjustOrderBySolDelta(swapInfo)
 					return swapInfo, nil
 				}
 			}
 		}
 
+		p.trace(swapInfo, "pump.fun legs fall through to leg aggregation")
 		otherSwaps = append(otherSwaps, pumpfunSwaps...)
 	}
 
@@ -415,6 +572,17 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 			case *OKXSwapEventData:
 				key := fmt.Sprintf("okxevt|%s|%d", v.InputMint.String(), v.InputAmount)
 				record(v.InputMint.String(), v.InputAmount, v.InputDecimals, key)
+
+			case *PumpSwapTradeEvent:
+				inMint, inAmt, outMint, outAmt := v.sides()
+				key := fmt.Sprintf("pswap|%s|%s|%t|%d", v.Pool, v.User, v.IsBuy, v.BaseAmount)
+				record(inMint.String(), inAmt, p.mintDecimals(inMint), key+"|in")
+				record(outMint.String(), outAmt, p.mintDecimals(outMint), key+"|out")
+
+				if v.User.Equals(signer) && !seenSignerOut[key] {
+					signerOutByMint[inMint.String()] += inAmt
+					seenSignerOut[key] = true
+				}
 			}
 		}
 
@@ -446,7 +614,8 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 								seenAMMs[string(sd.Type)] = true
 							}
 						}
-						swapInfo.Timestamp = time.Now()
+						p.trace(swapInfo, "trader lamports +%d: SOL is the output", delta)
+						p.setMethod(swapInfo, MethodLamportDeltaSell)
 						return swapInfo, nil
 					}
 				}
@@ -477,7 +646,7 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 							seenAMMs[string(sd.Type)] = true
 						}
 					}
-					swapInfo.Timestamp = time.Now()
+					p.setMethod(swapInfo, MethodSignerOutflow)
 					p.adjustOrderBySolDelta(swapInfo)
 					return swapInfo, nil
 				}
@@ -502,18 +671,16 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 				var totalOutputAmount uint64
 
 				for _, sd := range otherSwaps {
-					tr := getTransferFromSwapData(sd)
-					if tr == nil {
-						continue
-					}
-					key := fmt.Sprintf("%d-%s", tr.amount, tr.mint)
-					if tr.mint == inputTransfer.mint && !seenInputs[key] {
-						totalInputAmount += tr.amount
-						seenInputs[key] = true
-					}
-					if tr.mint == outputTransfer.mint && !seenOutputs[key] {
-						totalOutputAmount += tr.amount
-						seenOutputs[key] = true
+					for _, tr := range swapDataTransfers(sd) {
+						key := fmt.Sprintf("%d-%s", tr.amount, tr.mint)
+						if tr.mint == inputTransfer.mint && !seenInputs[key] {
+							totalInputAmount += tr.amount
+							seenInputs[key] = true
+						}
+						if tr.mint == outputTransfer.mint && !seenOutputs[key] {
+							totalOutputAmount += tr.amount
+							seenOutputs[key] = true
+						}
 					}
 				}
 
@@ -532,7 +699,8 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 					}
 				}
 
-				swapInfo.Timestamp = time.Now()
+				p.trace(swapInfo, "%d mints seen; first in, last out", len(uniqueTokens))
+				p.setMethod(swapInfo, MethodFirstLastMint)
 				p.adjustOrderBySolDelta(swapInfo)
 				return swapInfo, nil
 			}
@@ -542,6 +710,22 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 	return nil, fmt.Errorf("no valid swaps found")
 }
 
+// swapDataTransfers returns the token movements of a leg: both sides for
+// events that report them, otherwise the single transfer (if any).
+func swapDataTransfers(swapData SwapData) []TokenTransfer {
+	if v, ok := swapData.Data.(*PumpSwapTradeEvent); ok {
+		inMint, inAmt, outMint, outAmt := v.sides()
+		return []TokenTransfer{
+			{mint: inMint.String(), amount: inAmt},
+			{mint: outMint.String(), amount: outAmt},
+		}
+	}
+	if tr := getTransferFromSwapData(swapData); tr != nil {
+		return []TokenTransfer{*tr}
+	}
+	return nil
+}
+
 func getTransferFromSwapData(swapData SwapData) *TokenTransfer {
 	switch data := swapData.Data.(type) {
 	case *TransferData:
@@ -586,48 +770,35 @@ func (p *Parser) processRouterSwaps(instructionIndex int) []SwapData {
 
 	processedProtocols := make(map[string]bool)
 
-	for _, inner := range innerInstructions {
-		progID := p.allAccountKeys[inner.ProgramIDIndex]
-
-		switch {
-		case (progID.Equals(RAYDIUM_V4_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CPMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID)) && !processedProtocols[PROTOCOL_RAYDIUM]:
-			processedProtocols[PROTOCOL_RAYDIUM] = true
-			if raydSwaps := p.processRaydSwaps(instructionIndex); len(raydSwaps) > 0 {
-				swaps = append(swaps, raydSwaps...)
-			}
-
-		case progID.Equals(ORCA_PROGRAM_ID) && !processedProtocols[PROTOCOL_ORCA]:
-			processedProtocols[PROTOCOL_ORCA] = true
-			if orcaSwaps := p.processOrcaSwaps(instructionIndex); len(orcaSwaps) > 0 {
-				swaps = append(swaps, orcaSwaps...)
-			}
-
-		case (progID.Equals(METEORA_PROGRAM_ID) ||
-			progID.Equals(METEORA_POOLS_PROGRAM_ID) ||
-			progID.Equals(METEORA_DLMM_PROGRAM_ID) ||
-			progID.Equals(METEORA_DBC_PROGRAM_ID) ||
-			progID.Equals(METEORA_DAMM_V2_PROGRAM_ID)) && !processedProtocols[PROTOCOL_METEORA]:
-			processedProtocols[PROTOCOL_METEORA] = true
-			if meteoraSwaps := p.processMeteoraSwaps(instructionIndex); len(meteoraSwaps) > 0 {
-				swaps = append(swaps, meteoraSwaps...)
-			}
-
-		case progID.Equals(PUMPFUN_AMM_PROGRAM_ID) && !processedProtocols[PROTOCOL_PUMPFUN]:
-			processedProtocols[PROTOCOL_PUMPFUN] = true
-			if pumpfunAMMSwaps := p.processPumpfunAMMSwaps(instructionIndex); len(pumpfunAMMSwaps) > 0 {
-				swaps = append(swaps, pumpfunAMMSwaps...)
-			}
+	// With stack heights, decoders that can are given each AMM invocation on
+	// its own, so a protocol's legs don't include transfers of other hops.
+	if p.hasStackHeights(instructionIndex) {
+		root := p.InstructionTree()[instructionIndex]
+		for _, c := range root.Children {
+			c.Walk(func(n *InstructionNode) bool {
+				if !p.isHopInvocation(n.Instruction) {
+					return true
+				}
+				d := p.decoderFor(n.ProgramID)
+				if nd, ok := d.(NodeDecoder); ok {
+					swaps = append(swaps, nd.DecodeNode(p, n)...)
+				} else if !processedProtocols[d.Name()] {
+					processedProtocols[d.Name()] = true
+					swaps = append(swaps, d.Decode(p, instructionIndex)...)
+				}
+				return true
+			})
+		}
+		return swaps
+	}
 
-		case (progID.Equals(PUMP_FUN_PROGRAM_ID) ||
-			progID.Equals(solana.MustPublicKeyFromBase58("BSfD6SHZigAfDWSjzD5Q41jw8LmKwtmjskPH9XW1mrRW"))) && !processedProtocols[PROTOCOL_PUMPFUN]:
-			processedProtocols[PROTOCOL_PUMPFUN] = true
-			if pumpfunSwaps := p.processPumpfunSwaps(instructionIndex); len(pumpfunSwaps) > 0 {
-				swaps = append(swaps, pumpfunSwaps...)
-			}
+	for _, inner := range innerInstructions {
+		d := p.decoderFor(p.allAccountKeys[inner.ProgramIDIndex])
+		if d == nil || d.Role() != RoleAMM || processedProtocols[d.Name()] {
+			continue
 		}
+		processedProtocols[d.Name()] = true
+		swaps = append(swaps, d.Decode(p, instructionIndex)...)
 	}
 
 	return swaps
@@ -656,9 +827,13 @@ func (p *Parser) getInnerInstructions(index int) []solana.CompiledInstruction {
 var pumpfunBuyDisc = []byte{102, 6, 61, 18, 1, 218, 235, 234}     // "buy"
 var pumpfunSellDisc = []byte{51, 230, 133, 164, 1, 127, 131, 173} // "sell"
 
-// detectPumpfunBuySell scans outer instructions for Pump.fun and returns (found, isBuy)
-func (p *Parser) detectPumpfunBuySell() (bool, bool) {
-	for _, ci := range p.txInfo.Message.Instructions {
+// detectPumpfunBuySell scans outer instructions for Pump.fun and returns (found, isBuy).
+// scope limits the scan to one outer instruction; -1 scans all of them.
+func (p *Parser) detectPumpfunBuySell(scope int) (bool, bool) {
+	for i, ci := range p.txInfo.Message.Instructions {
+		if scope >= 0 && i != scope {
+			continue
+		}
 		progID := p.allAccountKeys[ci.ProgramIDIndex]
 		if !(progID.Equals(PUMP_FUN_PROGRAM_ID) || progID.Equals(solana.MustPublicKeyFromBase58("BSfD6SHZigAfDWSjzD5Q41jw8LmKwtmjskPH9XW1mrRW"))) {
 			continue
@@ -712,17 +887,18 @@ func (p *Parser) adjustOrderBySolDelta(si *SwapInfo) {
 	if !(si.TokenInMint.Equals(solMint) || si.TokenOutMint.Equals(solMint)) {
 		return
 	}
-	// Need signer lamport delta
-	if len(si.Signers) == 0 {
+	// Need trader lamport delta
+	if si.Trader.IsZero() {
 		return
 	}
-	delta, ok := p.lamportDeltaFor(si.Signers[0])
+	delta, ok := p.lamportDeltaFor(si.Trader)
 	if !ok || delta <= 0 {
-		// Only enforce when signer SOL increased (definitive sell). Otherwise do nothing.
+		// Only enforce when trader SOL increased (definitive sell). Otherwise do nothing.
 		return
 	}
-	// delta > 0 means signer received SOL (SELL) ⇒ SOL must be TokenOut.
+	// delta > 0 means trader received SOL (SELL) ⇒ SOL must be TokenOut.
 	if si.TokenInMint.Equals(solMint) {
+		p.trace(si, "trader lamports +%d: flipped in/out so SOL is the output", delta)
 		p.swapInOut(si)
 	}
 }
@@ -0,0 +1,50 @@
+package solanaswapgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sirupsen/logrus"
+)
+
+func TestProcessSwapData_UsesBlockInfo(t *testing.T) {
+	p, _ := newRaydiumV4Parser(t)
+	blockTime := solana.UnixTimeSeconds(1_700_000_000)
+	p.SetBlockInfo(250_000_000, &blockTime)
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	info, err := p.ProcessSwapData(swaps)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if info.Slot != 250_000_000 {
+		t.Errorf("Slot = %d, want 250000000", info.Slot)
+	}
+	if !info.Timestamp.Equal(time.Unix(1_700_000_000, 0)) {
+		t.Errorf("Timestamp = %s, want block time", info.Timestamp)
+	}
+}
+
+func TestProcessSwapData_MethodAndTrace(t *testing.T) {
+	p, _ := newRaydiumV4Parser(t)
+	p.Log.SetLevel(logrus.DebugLevel)
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	info, err := p.ProcessSwapData(swaps)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if info.Method != MethodSignerOutflow || info.Confidence != methodConfidence[MethodSignerOutflow] {
+		t.Errorf("Method/Confidence = %s/%v", info.Method, info.Confidence)
+	}
+	if len(info.Trace) == 0 {
+		t.Error("Trace empty at debug level")
+	}
+}
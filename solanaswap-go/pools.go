@@ -0,0 +1,171 @@
+package solanaswapgo
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// PoolCreated is a new pool, either initialized directly or created by a
+// launchpad migrating a graduated token.
+type PoolCreated struct {
+	ProgramID solana.PublicKey
+	Protocol  string // PROTOCOL_RAYDIUM, PROTOCOL_ORCA, PROTOCOL_METEORA, PROTOCOL_PUMPFUN
+	Pool      solana.PublicKey
+	Creator   solana.PublicKey
+
+	// MintA/MintB follow the program's own order (coin/pc, base/quote,
+	// token 0/1, A/B); ReserveA/ReserveB are the pool vaults after creation.
+	MintA    solana.PublicKey
+	MintB    solana.PublicKey
+	ReserveA uint64
+	ReserveB uint64
+
+	// MigratedFrom is the launchpad (LAUNCH_PUMPFUN, LAUNCH_RAYDIUM_LAUNCHLAB,
+	// LAUNCH_METEORA_DBC) that created the pool for a graduated token; empty for
+	// plain pool creation.
+	MigratedFrom string
+
+	InstructionIndex int
+	Signature        solana.Signature
+	Slot             uint64
+	Timestamp        time.Time
+}
+
+var (
+	raydiumV4Initialize2Tag = byte(1)
+
+	raydiumCPMMInitializeDisc      = anchorDiscriminator8("initialize")
+	orcaInitializePoolDisc         = anchorDiscriminator8("initialize_pool")
+	orcaInitializePoolV2Disc       = anchorDiscriminator8("initialize_pool_v2")
+	pumpAMMCreatePoolDisc          = anchorDiscriminator8("create_pool")
+	dammV2InitializePoolDisc       = anchorDiscriminator8("initialize_pool")
+	dammV2InitializeDynamicDisc    = anchorDiscriminator8("initialize_pool_with_dynamic_config")
+	dammV2InitializeCustomDisc     = anchorDiscriminator8("initialize_customizable_pool")
+	dammV1InitializePoolDisc       = anchorDiscriminator8("initialize_permissionless_pool")
+	dammV1InitializeWithConfigDisc = anchorDiscriminator8("initialize_permissionless_constant_product_pool_with_config")
+	dammV1InitializeConfig2Disc    = anchorDiscriminator8("initialize_permissionless_constant_product_pool_with_config2")
+)
+
+// launchpadPrograms are the programs whose CPIs into a pool program are migrations.
+var launchpadPrograms = map[solana.PublicKey]string{
+	PUMP_FUN_PROGRAM_ID:          LAUNCH_PUMPFUN,
+	RAYDIUM_LAUNCHLAB_PROGRAM_ID: LAUNCH_RAYDIUM_LAUNCHLAB,
+	METEORA_DBC_PROGRAM_ID:       LAUNCH_METEORA_DBC,
+}
+
+// ParsePoolCreations returns the pools created in the transaction.
+func (p *Parser) ParsePoolCreations() ([]PoolCreated, error) {
+	if p.IsFailed() {
+		return nil, nil
+	}
+
+	var pools []PoolCreated
+	for i, outer := range p.txInfo.Message.Instructions {
+		migratedFrom := launchpadPrograms[p.allAccountKeys[outer.ProgramIDIndex]]
+		for _, inst := range append([]solana.CompiledInstruction{outer}, p.getInnerInstructions(i)...) {
+			pool := p.poolCreation(inst)
+			if pool == nil {
+				continue
+			}
+			pool.MigratedFrom = migratedFrom
+			pool.InstructionIndex = i
+			pool.Slot = p.slot
+			if len(p.txInfo.Signatures) > 0 {
+				pool.Signature = p.txInfo.Signatures[0]
+			}
+			if p.blockTime != nil {
+				pool.Timestamp = p.blockTime.Time()
+			}
+			pools = append(pools, *pool)
+		}
+	}
+	return pools, nil
+}
+
+// poolCreation decodes a pool initialization instruction. Account indices
+// follow each program's layout: pool, creator, mint A/B, vault A/B.
+func (p *Parser) poolCreation(inst solana.CompiledInstruction) *PoolCreated {
+	if int(inst.ProgramIDIndex) >= len(p.allAccountKeys) {
+		return nil
+	}
+	progID := p.allAccountKeys[inst.ProgramIDIndex]
+	disc, _ := prefix8(inst.Data)
+
+	type layout struct {
+		protocol                                    string
+		minAccounts                                 int
+		pool, creator, mintA, mintB, vaultA, vaultB int
+	}
+	var l layout
+	amounts := 8 // deposit amounts in the arguments, when there are no pool vaults
+	switch {
+	case progID.Equals(RAYDIUM_V4_PROGRAM_ID) && len(inst.Data) >= 26 && inst.Data[0] == raydiumV4Initialize2Tag:
+		l = layout{PROTOCOL_RAYDIUM, 21, 4, 17, 8, 9, 10, 11}
+	case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID) && disc == raydiumCPMMInitializeDisc:
+		l = layout{PROTOCOL_RAYDIUM, 12, 3, 0, 4, 5, 10, 11}
+	case progID.Equals(ORCA_PROGRAM_ID) && disc == orcaInitializePoolDisc:
+		l = layout{PROTOCOL_ORCA, 7, 4, 3, 1, 2, 5, 6}
+	case progID.Equals(ORCA_PROGRAM_ID) && disc == orcaInitializePoolV2Disc:
+		l = layout{PROTOCOL_ORCA, 9, 6, 5, 1, 2, 7, 8}
+	case progID.Equals(PUMPFUN_AMM_PROGRAM_ID) && disc == pumpAMMCreatePoolDisc:
+		l = layout{PROTOCOL_PUMPFUN, 11, 0, 2, 3, 4, 9, 10}
+	case progID.Equals(METEORA_DAMM_V2_PROGRAM_ID) && disc == dammV2InitializePoolDisc:
+		l = layout{PROTOCOL_METEORA, 12, 6, 0, 8, 9, 10, 11}
+	case progID.Equals(METEORA_DAMM_V2_PROGRAM_ID) && disc == dammV2InitializeDynamicDisc:
+		l = layout{PROTOCOL_METEORA, 13, 7, 0, 9, 10, 11, 12}
+	case progID.Equals(METEORA_DAMM_V2_PROGRAM_ID) && disc == dammV2InitializeCustomDisc:
+		l = layout{PROTOCOL_METEORA, 11, 5, 0, 7, 8, 9, 10}
+	case progID.Equals(METEORA_POOLS_PROGRAM_ID) && (disc == dammV1InitializeWithConfigDisc || disc == dammV1InitializeConfig2Disc):
+		// Tokens sit in Meteora vaults, not pool-owned accounts: the deposit
+		// amounts come from the arguments instead (vaultA/B unused).
+		l = layout{PROTOCOL_METEORA, 19, 0, 18, 3, 4, -1, -1}
+	case progID.Equals(METEORA_POOLS_PROGRAM_ID) && disc == dammV1InitializePoolDisc:
+		l = layout{PROTOCOL_METEORA, 16, 0, 15, 2, 3, -1, -1}
+		amounts += meteoraCurveTypeLen(inst.Data)
+	default:
+		return nil
+	}
+	if len(inst.Accounts) < l.minAccounts {
+		return nil
+	}
+	key := func(i int) solana.PublicKey { return p.instructionAccount(inst, i) }
+	pool := &PoolCreated{
+		ProgramID: progID,
+		Protocol:  l.protocol,
+		Pool:      key(l.pool),
+		Creator:   key(l.creator),
+		MintA:     key(l.mintA),
+		MintB:     key(l.mintB),
+	}
+	if l.vaultA >= 0 {
+		pool.ReserveA, _ = p.postTokenAmount(inst.Accounts[l.vaultA])
+		pool.ReserveB, _ = p.postTokenAmount(inst.Accounts[l.vaultB])
+	} else {
+		pool.ReserveA, _ = readU64(inst.Data, amounts)
+		pool.ReserveB, _ = readU64(inst.Data, amounts+8)
+	}
+	return pool
+}
+
+// meteoraCurveTypeLen is the Borsh size of the CurveType argument that leads
+// initialize_permissionless_pool: ConstantProduct, or Stable{amp,
+// token_multiplier, depeg, last_amp_updated_timestamp}.
+func meteoraCurveTypeLen(data []byte) int {
+	if len(data) > 8 && data[8] == 1 {
+		return 1 + 8 + 17 + 17 + 8
+	}
+	return 1
+}
+
+// postTokenAmount is the token balance of the account at index after the transaction.
+func (p *Parser) postTokenAmount(index uint16) (uint64, bool) {
+	for _, b := range p.txMeta.PostTokenBalances {
+		if b.AccountIndex == index && b.UiTokenAmount != nil {
+			amt, err := strconv.ParseUint(b.UiTokenAmount.Amount, 10, 64)
+			return amt, err == nil
+		}
+	}
+	return 0, false
+}
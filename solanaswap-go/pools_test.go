@@ -0,0 +1,239 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var pumpMigrateDisc = anchorDiscriminator8("migrate")
+
+func TestParsePoolCreations_PumpfunMigration(t *testing.T) {
+	pool, globalConfig, mint, lpMint, userBase, userQuote, userLP, poolBase, poolQuote :=
+		testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), testKey(6), testKey(7), testKey(8), testKey(9)
+	keys := []solana.PublicKey{
+		testPayer, pool, globalConfig, mint, NATIVE_SOL_MINT_PROGRAM_ID, lpMint, // 0-5
+		userBase, userQuote, userLP, poolBase, poolQuote, // 6-10
+		solana.TokenProgramID, PUMP_FUN_PROGRAM_ID, PUMPFUN_AMM_PROGRAM_ID, // 11-13
+	}
+
+	createPool := append([]byte{}, pumpAMMCreatePoolDisc[:]...)
+	createPool = binary.LittleEndian.AppendUint16(createPool, 0)
+	createPool = binary.LittleEndian.AppendUint64(createPool, 206_900_000_000_000)
+	createPool = binary.LittleEndian.AppendUint64(createPool, 84_990_359_064)
+	mintTo := append([]byte{7}, make([]byte, 8)...)
+
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 13, Accounts: []uint16{1, 2, 0, 3, 4, 5, 6, 7, 8, 9, 10}, Data: createPool},
+				{ProgramIDIndex: 11, Accounts: []uint16{5, 8, 1}, Data: mintTo},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(9, mint.String(), pool, "206900000000000", 6),
+			tokenBalance(10, NATIVE_SOL_MINT_PROGRAM_ID.String(), pool, "84990359064", 9),
+		},
+	}
+	p := newTestParser(t, keys, []solana.CompiledInstruction{
+		{ProgramIDIndex: 12, Accounts: []uint16{0, 3, 1}, Data: pumpMigrateDisc[:]},
+	}, meta)
+
+	if op := p.DetectLiquidityOp(); op != LiquidityPoolCreate {
+		t.Fatalf("DetectLiquidityOp = %d, want LiquidityPoolCreate", op)
+	}
+	if swaps, err := p.ParseTransaction(); err != nil || swaps != nil {
+		t.Errorf("ParseTransaction = %v, %v; want nil", swaps, err)
+	}
+
+	pools, err := p.ParsePoolCreations()
+	if err != nil {
+		t.Fatalf("ParsePoolCreations: %v", err)
+	}
+	if len(pools) != 1 {
+		t.Fatalf("got %d pools, want 1", len(pools))
+	}
+	got := pools[0]
+	if got.MigratedFrom != LAUNCH_PUMPFUN || got.Protocol != PROTOCOL_PUMPFUN || !got.Pool.Equals(pool) || !got.Creator.Equals(testPayer) {
+		t.Errorf("pool = %+v", got)
+	}
+	if !got.MintA.Equals(mint) || !got.MintB.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+		t.Errorf("mints = %s/%s", got.MintA, got.MintB)
+	}
+	if got.ReserveA != 206_900_000_000_000 || got.ReserveB != 84_990_359_064 {
+		t.Errorf("reserves = %d/%d", got.ReserveA, got.ReserveB)
+	}
+}
+
+func TestParsePoolCreations_RaydiumV4Initialize2(t *testing.T) {
+	keys := make([]solana.PublicKey, 22)
+	for i := range keys {
+		keys[i] = testKey(byte(i + 1))
+	}
+	keys[17] = testPayer
+	keys[21] = RAYDIUM_V4_PROGRAM_ID
+	accounts := make([]uint16, 21)
+	for i := range accounts {
+		accounts[i] = uint16(i)
+	}
+
+	data := []byte{1, 254}
+	data = binary.LittleEndian.AppendUint64(data, 0)              // open_time
+	data = binary.LittleEndian.AppendUint64(data, 50_000_000_000) // init_pc_amount
+	data = binary.LittleEndian.AppendUint64(data, 1_000_000_000)  // init_coin_amount
+	meta := &rpc.TransactionMeta{
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(10, keys[8].String(), keys[5], "1000000000", 6),
+			tokenBalance(11, keys[9].String(), keys[5], "50000000000", 9),
+		},
+	}
+	p := newTestParser(t, keys, []solana.CompiledInstruction{{ProgramIDIndex: 21, Accounts: accounts, Data: data}}, meta)
+
+	pools, err := p.ParsePoolCreations()
+	if err != nil || len(pools) != 1 {
+		t.Fatalf("ParsePoolCreations = %v, %v; want one pool", pools, err)
+	}
+	got := pools[0]
+	if got.MigratedFrom != "" || !got.Pool.Equals(keys[4]) || !got.MintA.Equals(keys[8]) || !got.MintB.Equals(keys[9]) || !got.Creator.Equals(testPayer) {
+		t.Errorf("pool = %+v", got)
+	}
+	if got.ReserveA != 1_000_000_000 || got.ReserveB != 50_000_000_000 {
+		t.Errorf("reserves = %d/%d", got.ReserveA, got.ReserveB)
+	}
+}
+
+func TestParseTransaction_CreateAndSwap(t *testing.T) {
+	swap, _ := newRaydiumV4Parser(t)
+	tx, meta := *swap.txInfo, *swap.txMeta
+	swapIx := tx.Message.Instructions[0]
+	// initialize2 deposits into both vaults, then the creator buys in the same transaction
+	tx.Message.Instructions = []solana.CompiledInstruction{
+		{ProgramIDIndex: 8, Accounts: []uint16{7, 5, 6, 3, 4, 1, 2, 0}, Data: []byte{1, 254}},
+		swapIx,
+	}
+	deposit := rpc.InnerInstruction{Index: 0, Instructions: []rpc.CompiledInstruction{
+		{ProgramIDIndex: 7, Accounts: []uint16{1, 3, 0}, Data: transferData(5_000_000)},
+		{ProgramIDIndex: 7, Accounts: []uint16{2, 4, 0}, Data: transferData(900_000)},
+	}}
+	trade := meta.InnerInstructions[0]
+	trade.Index = 1
+	meta.InnerInstructions = []rpc.InnerInstruction{deposit, trade}
+
+	p, err := NewTransactionParserFromTransaction(&tx, &meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := p.DetectLiquidityOp(); op != LiquidityPoolCreate {
+		t.Fatalf("op = %s", op)
+	}
+	legs, err := p.ParseTransaction()
+	if err != nil || len(legs) == 0 {
+		t.Fatalf("ParseTransaction = %v, %v; want the swap legs", legs, err)
+	}
+	swaps, err := p.ProcessAllSwaps(legs)
+	if err != nil || len(swaps) != 1 {
+		t.Fatalf("ProcessAllSwaps = %+v, %v; want one swap", swaps, err)
+	}
+	if s := swaps[0]; s.InstructionIndex != 1 || s.TokenInAmount != 1_000_000 || s.TokenOutAmount != 42_000 {
+		t.Errorf("swap = instruction %d, %d → %d", s.InstructionIndex, s.TokenInAmount, s.TokenOutAmount)
+	}
+}
+
+func TestParsePoolCreations_MeteoraPermissionless(t *testing.T) {
+	custom := append([]byte{}, dammV2InitializeCustomDisc[:]...)
+	permissionless := append(append([]byte{}, dammV1InitializePoolDisc[:]...), 0) // ConstantProduct
+	permissionless = binary.LittleEndian.AppendUint64(permissionless, 1_000_000_000)
+	permissionless = binary.LittleEndian.AppendUint64(permissionless, 50_000_000_000)
+
+	for _, tc := range []struct {
+		name                         string
+		program                      solana.PublicKey
+		data                         []byte
+		accounts                     int
+		pool, creator, mintA, vaultA int
+	}{
+		{"damm v2 customizable", METEORA_DAMM_V2_PROGRAM_ID, custom, 19, 5, 0, 7, 9},
+		{"pools permissionless", METEORA_POOLS_PROGRAM_ID, permissionless, 24, 0, 15, 2, -1},
+	} {
+		keys := make([]solana.PublicKey, tc.accounts)
+		accounts := make([]uint16, tc.accounts)
+		for i := range keys {
+			keys[i], accounts[i] = testKey(byte(i+1)), uint16(i)
+		}
+		keys[tc.creator] = testPayer
+		keys = append(keys, solana.TokenProgramID, tc.program)
+		tokenProgram, program := uint16(tc.accounts), uint16(tc.accounts+1)
+
+		// the creator's deposit into the new pool looks like a transfer pair
+		meta := &rpc.TransactionMeta{
+			InnerInstructions: []rpc.InnerInstruction{{Index: 0, Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: tokenProgram, Accounts: []uint16{11, 9, uint16(tc.creator)}, Data: transferData(1_000_000_000)},
+				{ProgramIDIndex: tokenProgram, Accounts: []uint16{12, 10, uint16(tc.creator)}, Data: transferData(50_000_000_000)},
+			}}},
+		}
+		if tc.vaultA >= 0 {
+			meta.PostTokenBalances = []rpc.TokenBalance{
+				tokenBalance(uint16(tc.vaultA), keys[tc.mintA].String(), keys[4], "1000000000", 6),
+				tokenBalance(uint16(tc.vaultA+1), keys[tc.mintA+1].String(), keys[4], "50000000000", 9),
+			}
+		}
+		p := newTestParser(t, keys, []solana.CompiledInstruction{{ProgramIDIndex: program, Accounts: accounts, Data: tc.data}}, meta)
+
+		if swaps, err := p.ParseTransaction(); err != nil || swaps != nil {
+			t.Errorf("%s: ParseTransaction = %v, %v; want nil", tc.name, swaps, err)
+		}
+		pools, err := p.ParsePoolCreations()
+		if err != nil || len(pools) != 1 {
+			t.Fatalf("%s: ParsePoolCreations = %v, %v; want one pool", tc.name, pools, err)
+		}
+		got := pools[0]
+		if !got.Pool.Equals(keys[tc.pool]) || !got.Creator.Equals(testPayer) || !got.MintA.Equals(keys[tc.mintA]) || !got.MintB.Equals(keys[tc.mintA+1]) {
+			t.Errorf("%s: pool = %+v", tc.name, got)
+		}
+		if got.ReserveA != 1_000_000_000 || got.ReserveB != 50_000_000_000 {
+			t.Errorf("%s: reserves = %d/%d", tc.name, got.ReserveA, got.ReserveB)
+		}
+	}
+}
+
+func TestParsePoolCreations_UnresolvedLookupTable(t *testing.T) {
+	// An unsent v0 initialize2 whose mints and vaults come from a lookup table
+	// that hasn't been resolved: their indexes are past the static keys.
+	keys := []solana.PublicKey{testPayer, testKey(1), RAYDIUM_V4_PROGRAM_ID}
+	accounts := make([]uint16, 21)
+	for i := range accounts {
+		accounts[i] = uint16(3 + i)
+	}
+	accounts[17] = 0
+	data := []byte{1, 254}
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	data = binary.LittleEndian.AppendUint64(data, 50_000_000_000)
+	data = binary.LittleEndian.AppendUint64(data, 1_000_000_000)
+	tx := &solana.Transaction{Message: solana.Message{
+		Header:      solana.MessageHeader{NumRequiredSignatures: 1},
+		AccountKeys: keys,
+		Instructions: []solana.CompiledInstruction{
+			{ProgramIDIndex: 2, Accounts: accounts, Data: data},
+			{ProgramIDIndex: 2, Accounts: append([]uint16{1}, accounts...), Data: binary.LittleEndian.AppendUint64([]byte{4}, 500)},
+		},
+		AddressTableLookups: solana.MessageAddressTableLookupSlice{{AccountKey: testKey(9), WritableIndexes: make([]uint8, 21)}},
+	}}
+	p, err := NewTransactionParserFromTransaction(tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pools, err := p.ParsePoolCreations()
+	if err != nil || len(pools) != 1 {
+		t.Fatalf("ParsePoolCreations = %v, %v; want one pool", pools, err)
+	}
+	if got := pools[0]; !got.Creator.Equals(testPayer) || !got.MintA.IsZero() || !got.Pool.IsZero() {
+		t.Errorf("pool = %+v", got)
+	}
+	if _, err := p.ParseLiquidity(); err != nil {
+		t.Errorf("ParseLiquidity: %v", err)
+	}
+}
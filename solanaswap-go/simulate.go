@@ -0,0 +1,340 @@
+package solanaswapgo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// RPCCaller is the JSON-RPC method of *rpc.Client the simulation and lookup
+// table helpers use; a stand-in can serve canned responses.
+type RPCCaller interface {
+	RPCCallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error
+}
+
+// SimulationResult is a transaction parsed from its simulated execution.
+type SimulationResult struct {
+	// Parser is built on the simulated meta, for anything beyond the swap.
+	Parser *Parser
+	// Swap is the expected swap; nil if the simulation failed or no decoder
+	// recognized one.
+	Swap *SwapInfo
+	// Failed is the attempted swap when the simulation failed.
+	Failed        *FailedSwap
+	Err           interface{} // simulation error, as meta.err
+	Logs          []string
+	UnitsConsumed uint64
+	Slot          uint64
+}
+
+// lamportsPerSignature is the base fee; simulateTransaction doesn't report the
+// fee, so it is estimated from the signatures and the compute budget.
+const lamportsPerSignature = 5000
+
+// SimulateAndParse simulates tx (unsigned is fine: signatures aren't verified
+// and the blockhash is replaced) and parses the result with the regular
+// decoders. The meta is assembled from the simulated logs and inner
+// instructions, and from the writable accounts before (getMultipleAccounts)
+// and after (the simulation's account snapshots) for the lamport and token
+// balances. Both are taken at the same slot, so the balance changes are the
+// transaction's own; the pair is retried when the node moves on in between.
+// Lookup tables are resolved through the same client.
+func SimulateAndParse(ctx context.Context, client RPCCaller, tx *solana.Transaction) (*SimulationResult, error) {
+	writable, readonly, err := resolveLookups(ctx, tx.Message.AddressTableLookups, NewRPCALTResolver(client))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve lookup tables: %w", err)
+	}
+	keys := append(append(append(solana.PublicKeySlice{}, tx.Message.AccountKeys...), writable...), readonly...)
+	snapshot := writableKeys(&tx.Message, writable)
+	if len(snapshot) > maxSimulationAccounts {
+		return nil, fmt.Errorf("transaction writes %d accounts, simulateTransaction snapshots at most %d", len(snapshot), maxSimulationAccounts)
+	}
+
+	var pre []*accountData
+	var sim *simulationResponse
+	for attempt := 1; ; attempt++ {
+		var slot uint64
+		pre, slot, err = getMultipleAccountsAt(ctx, client, snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch accounts: %w", err)
+		}
+		sim, err = simulateTransaction(ctx, client, tx, snapshot, slot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate transaction: %w", err)
+		}
+		if sim.Context.Slot == slot {
+			break
+		}
+		if attempt == maxSimulationAttempts {
+			return nil, fmt.Errorf("simulation ran at slot %d, accounts were fetched at slot %d", sim.Context.Slot, slot)
+		}
+	}
+
+	meta := &rpc.TransactionMeta{
+		Err:                  sim.Value.Err,
+		LogMessages:          sim.Value.Logs,
+		PreBalances:          make([]uint64, len(keys)),
+		PostBalances:         make([]uint64, len(keys)),
+		LoadedAddresses:      rpc.LoadedAddresses{Writable: writable, ReadOnly: readonly},
+		ComputeUnitsConsumed: sim.Value.UnitsConsumed,
+	}
+	index := make(map[solana.PublicKey]uint16, len(keys))
+	for i, k := range keys {
+		if _, ok := index[k]; !ok {
+			index[k] = uint16(i)
+		}
+	}
+	for _, set := range sim.Value.InnerInstructions {
+		inner := rpc.InnerInstruction{Index: set.Index}
+		for i := range set.Instructions {
+			ci, err := set.Instructions[i].compile(index)
+			if err != nil {
+				return nil, err
+			}
+			inner.Instructions = append(inner.Instructions, ci)
+		}
+		meta.InnerInstructions = append(meta.InnerInstructions, inner)
+	}
+
+	// A failed simulation changes nothing but the fee and returns no
+	// snapshots; after a successful one a missing snapshot is a closed account.
+	post := pre
+	if sim.Value.Err == nil && len(sim.Value.Accounts) == len(snapshot) {
+		post = sim.Value.Accounts
+	}
+
+	// Token balances of the snapshot accounts, with decimals from their mints.
+	var mints []solana.PublicKey
+	for _, set := range [][]*accountData{pre, post} {
+		for _, acc := range set {
+			if ta, ok := acc.tokenAccount(); ok && !containsKey(mints, ta.mint) {
+				mints = append(mints, ta.mint)
+			}
+		}
+	}
+	decimals := make(map[solana.PublicKey]uint8, len(mints))
+	if len(mints) > 0 {
+		mintAccounts, err := getMultipleAccounts(ctx, client, mints)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch mints: %w", err)
+		}
+		for i, acc := range mintAccounts {
+			if acc != nil && len(acc.Data) > 44 {
+				decimals[mints[i]] = acc.Data[44]
+			}
+		}
+	}
+	for i, k := range snapshot {
+		idx := index[k]
+		if acc := pre[i]; acc != nil {
+			meta.PreBalances[idx] = acc.Lamports
+			if ta, ok := acc.tokenAccount(); ok {
+				meta.PreTokenBalances = append(meta.PreTokenBalances, ta.balance(idx, decimals[ta.mint]))
+			}
+		}
+		if acc := post[i]; acc != nil {
+			meta.PostBalances[idx] = acc.Lamports
+			if ta, ok := acc.tokenAccount(); ok {
+				meta.PostTokenBalances = append(meta.PostTokenBalances, ta.balance(idx, decimals[ta.mint]))
+			}
+		}
+	}
+
+	p, err := NewTransactionParserFromTransaction(tx, meta)
+	if err != nil {
+		return nil, err
+	}
+	var budget Fees
+	p.decodeComputeBudget(&budget)
+	meta.Fee = lamportsPerSignature*uint64(tx.Message.Header.NumRequiredSignatures) + budget.PriorityFee
+	p.SetBlockInfo(sim.Context.Slot, nil)
+
+	res := &SimulationResult{
+		Parser: p,
+		Err:    sim.Value.Err,
+		Logs:   sim.Value.Logs,
+		Slot:   sim.Context.Slot,
+	}
+	if sim.Value.UnitsConsumed != nil {
+		res.UnitsConsumed = *sim.Value.UnitsConsumed
+	}
+	if p.IsFailed() {
+		res.Failed, _ = p.ParseFailedSwap()
+		return res, nil
+	}
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		return nil, err
+	}
+	if len(swaps) > 0 {
+		res.Swap, err = p.ProcessSwapData(swaps)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// maxSimulationAccounts is the RPC limit on simulateTransaction account
+// snapshots and getMultipleAccounts.
+const maxSimulationAccounts = 100
+
+// maxSimulationAttempts bounds the fetch-and-simulate retries when the node
+// advances a slot between the two calls.
+const maxSimulationAttempts = 3
+
+// writableKeys lists the writable static keys followed by the writable keys
+// loaded from lookup tables, in account key order.
+func writableKeys(msg *solana.Message, loaded []solana.PublicKey) []solana.PublicKey {
+	h := msg.Header
+	n := len(msg.AccountKeys)
+	var keys []solana.PublicKey
+	for i, k := range msg.AccountKeys {
+		signer := i < int(h.NumRequiredSignatures)
+		if signer && i < int(h.NumRequiredSignatures-h.NumReadonlySignedAccounts) ||
+			!signer && i < n-int(h.NumReadonlyUnsignedAccounts) {
+			keys = append(keys, k)
+		}
+	}
+	return append(keys, loaded...)
+}
+
+type rpcContextSlot struct {
+	Slot uint64 `json:"slot"`
+}
+
+// accountData is a base64-encoded account as returned by getMultipleAccounts
+// and simulateTransaction.
+type accountData struct {
+	Lamports uint64
+	Owner    solana.PublicKey
+	Data     []byte
+}
+
+func (a *accountData) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Lamports uint64           `json:"lamports"`
+		Owner    solana.PublicKey `json:"owner"`
+		Data     []string         `json:"data"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	a.Lamports, a.Owner = raw.Lamports, raw.Owner
+	if len(raw.Data) > 0 {
+		data, err := base64.StdEncoding.DecodeString(raw.Data[0])
+		if err != nil {
+			return fmt.Errorf("invalid account data: %w", err)
+		}
+		a.Data = data
+	}
+	return nil
+}
+
+type tokenAccountState struct {
+	mint, owner solana.PublicKey
+	amount      uint64
+	programID   solana.PublicKey
+}
+
+// tokenAccount decodes an initialized SPL Token / Token-2022 account.
+func (a *accountData) tokenAccount() (tokenAccountState, bool) {
+	if a == nil || len(a.Data) < 165 || a.Data[108] == 0 ||
+		!(a.Owner.Equals(solana.TokenProgramID) || a.Owner.Equals(solana.Token2022ProgramID)) {
+		return tokenAccountState{}, false
+	}
+	return tokenAccountState{
+		mint:      solana.PublicKeyFromBytes(a.Data[0:32]),
+		owner:     solana.PublicKeyFromBytes(a.Data[32:64]),
+		amount:    binary.LittleEndian.Uint64(a.Data[64:72]),
+		programID: a.Owner,
+	}, true
+}
+
+func (t tokenAccountState) balance(index uint16, decimals uint8) rpc.TokenBalance {
+	owner, programID := t.owner, t.programID
+	return rpc.TokenBalance{
+		AccountIndex: index,
+		Owner:        &owner,
+		ProgramId:    &programID,
+		Mint:         t.mint,
+		UiTokenAmount: &rpc.UiTokenAmount{
+			Amount:   strconv.FormatUint(t.amount, 10),
+			Decimals: decimals,
+		},
+	}
+}
+
+func getMultipleAccounts(ctx context.Context, client RPCCaller, keys []solana.PublicKey) ([]*accountData, error) {
+	accounts, _, err := getMultipleAccountsAt(ctx, client, keys)
+	return accounts, err
+}
+
+// getMultipleAccountsAt also returns the slot the accounts were read at.
+func getMultipleAccountsAt(ctx context.Context, client RPCCaller, keys []solana.PublicKey) ([]*accountData, uint64, error) {
+	var out struct {
+		Context rpcContextSlot `json:"context"`
+		Value   []*accountData `json:"value"`
+	}
+	params := []interface{}{keys, map[string]interface{}{
+		"encoding":   solana.EncodingBase64,
+		"commitment": rpc.CommitmentConfirmed,
+	}}
+	if err := client.RPCCallForInto(ctx, &out, "getMultipleAccounts", params); err != nil {
+		return nil, 0, err
+	}
+	if len(out.Value) != len(keys) {
+		return nil, 0, fmt.Errorf("getMultipleAccounts returned %d accounts for %d keys", len(out.Value), len(keys))
+	}
+	return out.Value, out.Context.Slot, nil
+}
+
+type simulationResponse struct {
+	Context rpcContextSlot `json:"context"`
+	Value   struct {
+		Err               interface{}    `json:"err"`
+		Logs              []string       `json:"logs"`
+		Accounts          []*accountData `json:"accounts"`
+		UnitsConsumed     *uint64        `json:"unitsConsumed"`
+		InnerInstructions []struct {
+			Index        uint16                 `json:"index"`
+			Instructions []simulatedInstruction `json:"instructions"`
+		} `json:"innerInstructions"`
+	} `json:"value"`
+}
+
+// simulateTransaction simulates tx at minContextSlot or later.
+func simulateTransaction(ctx context.Context, client RPCCaller, tx *solana.Transaction, snapshot []solana.PublicKey, minContextSlot uint64) (*simulationResponse, error) {
+	// Unsigned transactions still need a (zeroed) slot per required signature.
+	signed := *tx
+	if missing := int(tx.Message.Header.NumRequiredSignatures) - len(tx.Signatures); missing > 0 {
+		signed.Signatures = append(append([]solana.Signature{}, tx.Signatures...), make([]solana.Signature, missing)...)
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	var out simulationResponse
+	params := []interface{}{base64.StdEncoding.EncodeToString(raw), map[string]interface{}{
+		"encoding":               solana.EncodingBase64,
+		"commitment":             rpc.CommitmentConfirmed,
+		"sigVerify":              false,
+		"replaceRecentBlockhash": true,
+		"innerInstructions":      true,
+		"minContextSlot":         minContextSlot,
+		"accounts": map[string]interface{}{
+			"encoding":  solana.EncodingBase64,
+			"addresses": snapshot,
+		},
+	}}
+	if err := client.RPCCallForInto(ctx, &out, "simulateTransaction", params); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
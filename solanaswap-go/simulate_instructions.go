@@ -0,0 +1,167 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/mr-tron/base58"
+)
+
+// simulatedInstruction is an inner instruction of a simulateTransaction
+// response. Nodes send them jsonParsed: token and system instructions as
+// {type, info}, other programs partially decoded with the program and accounts
+// as addresses. The compiled form, with account indexes, is accepted too.
+type simulatedInstruction struct {
+	ProgramIDIndex *uint16          `json:"programIdIndex"`
+	ProgramID      solana.PublicKey `json:"programId"`
+	Accounts       json.RawMessage  `json:"accounts"`
+	Data           string           `json:"data"`
+	Parsed         json.RawMessage  `json:"parsed"`
+	StackHeight    uint16           `json:"stackHeight"`
+}
+
+// compile converts the instruction to the compiled form of a fetched
+// transaction's meta, mapping addresses back through index.
+func (ri *simulatedInstruction) compile(index map[solana.PublicKey]uint16) (rpc.CompiledInstruction, error) {
+	out := rpc.CompiledInstruction{StackHeight: ri.StackHeight}
+	if ri.ProgramIDIndex != nil {
+		out.ProgramIDIndex = *ri.ProgramIDIndex
+		if len(ri.Accounts) > 0 {
+			if err := json.Unmarshal(ri.Accounts, &out.Accounts); err != nil {
+				return out, fmt.Errorf("invalid inner instruction accounts: %w", err)
+			}
+		}
+		data, err := base58.Decode(ri.Data)
+		if err != nil {
+			return out, fmt.Errorf("failed to decode inner instruction data: %w", err)
+		}
+		out.Data = data
+		return out, nil
+	}
+
+	prog, ok := index[ri.ProgramID]
+	if !ok {
+		return out, fmt.Errorf("inner instruction program %s is not an account of the transaction", ri.ProgramID)
+	}
+	out.ProgramIDIndex = prog
+
+	var accounts []solana.PublicKey
+	if len(ri.Parsed) > 0 {
+		var parsed parsedInstruction
+		if json.Unmarshal(ri.Parsed, &parsed) == nil {
+			var err error
+			if accounts, out.Data, err = parsed.rebuild(ri.ProgramID); err != nil {
+				return out, err
+			}
+		}
+	} else {
+		if len(ri.Accounts) > 0 {
+			if err := json.Unmarshal(ri.Accounts, &accounts); err != nil {
+				return out, fmt.Errorf("invalid inner instruction accounts: %w", err)
+			}
+		}
+		data, err := base58.Decode(ri.Data)
+		if err != nil {
+			return out, fmt.Errorf("failed to decode inner instruction data: %w", err)
+		}
+		out.Data = data
+	}
+	for _, acc := range accounts {
+		idx, ok := index[acc]
+		if !ok {
+			return out, fmt.Errorf("inner instruction account %s is not an account of the transaction", acc)
+		}
+		out.Accounts = append(out.Accounts, idx)
+	}
+	return out, nil
+}
+
+// parsedInstruction is a jsonParsed token or system instruction. Multisig
+// authorities replace the single authority and list their signers.
+type parsedInstruction struct {
+	Type string `json:"type"`
+	Info struct {
+		Source                solana.PublicKey   `json:"source"`
+		Destination           solana.PublicKey   `json:"destination"`
+		Account               solana.PublicKey   `json:"account"`
+		Mint                  solana.PublicKey   `json:"mint"`
+		Authority             solana.PublicKey   `json:"authority"`
+		MultisigAuthority     solana.PublicKey   `json:"multisigAuthority"`
+		MintAuthority         solana.PublicKey   `json:"mintAuthority"`
+		MultisigMintAuthority solana.PublicKey   `json:"multisigMintAuthority"`
+		Owner                 solana.PublicKey   `json:"owner"`
+		MultisigOwner         solana.PublicKey   `json:"multisigOwner"`
+		Signers               []solana.PublicKey `json:"signers"`
+		Amount                string             `json:"amount"`
+		Lamports              uint64             `json:"lamports"`
+		TokenAmount           struct {
+			Amount   string `json:"amount"`
+			Decimals uint8  `json:"decimals"`
+		} `json:"tokenAmount"`
+	} `json:"info"`
+}
+
+// rebuild re-encodes the instructions the decoders read: token transfers,
+// mints, burns and closes, and system transfers. Any other parsed instruction
+// keeps only its program.
+func (pi *parsedInstruction) rebuild(program solana.PublicKey) ([]solana.PublicKey, []byte, error) {
+	info := &pi.Info
+	signer := func(single, multisig solana.PublicKey) []solana.PublicKey {
+		if single.IsZero() {
+			return append([]solana.PublicKey{multisig}, info.Signers...)
+		}
+		return []solana.PublicKey{single}
+	}
+
+	if program.Equals(solana.SystemProgramID) {
+		if pi.Type != "transfer" {
+			return nil, nil, nil
+		}
+		data := binary.LittleEndian.AppendUint32(nil, 2)
+		return []solana.PublicKey{info.Source, info.Destination}, binary.LittleEndian.AppendUint64(data, info.Lamports), nil
+	}
+	if !program.Equals(solana.TokenProgramID) && !program.Equals(solana.Token2022ProgramID) {
+		return nil, nil, nil
+	}
+
+	var accounts []solana.PublicKey
+	var tag byte
+	checked := false
+	switch pi.Type {
+	case "transfer":
+		accounts, tag = append([]solana.PublicKey{info.Source, info.Destination}, signer(info.Authority, info.MultisigAuthority)...), 3
+	case "transferChecked":
+		accounts, tag, checked = append([]solana.PublicKey{info.Source, info.Mint, info.Destination}, signer(info.Authority, info.MultisigAuthority)...), 12, true
+	case "mintTo", "mintToChecked":
+		accounts, tag = append([]solana.PublicKey{info.Mint, info.Account}, signer(info.MintAuthority, info.MultisigMintAuthority)...), 7
+		checked = pi.Type == "mintToChecked"
+	case "burn", "burnChecked":
+		accounts, tag = append([]solana.PublicKey{info.Account, info.Mint}, signer(info.Authority, info.MultisigAuthority)...), 8
+		checked = pi.Type == "burnChecked"
+	case "closeAccount":
+		return append([]solana.PublicKey{info.Account, info.Destination}, signer(info.Owner, info.MultisigOwner)...), []byte{9}, nil
+	default:
+		return nil, nil, nil
+	}
+
+	amount := info.Amount
+	if checked {
+		amount = info.TokenAmount.Amount
+		if tag != 12 {
+			tag += 7 // MintToChecked = 14, BurnChecked = 15
+		}
+	}
+	n, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s amount %q: %w", pi.Type, amount, err)
+	}
+	data := binary.LittleEndian.AppendUint64([]byte{tag}, n)
+	if checked {
+		data = append(data, info.TokenAmount.Decimals)
+	}
+	return accounts, data, nil
+}
@@ -0,0 +1,247 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+// fakeRPC is a stand-in node: each call to a method returns the next canned
+// result queued for it.
+type fakeRPC struct {
+	results map[string][]interface{}
+	params  map[string][]interface{}
+}
+
+func (f *fakeRPC) RPCCallForInto(_ context.Context, out interface{}, method string, params []interface{}) error {
+	queue := f.results[method]
+	if len(queue) == 0 {
+		return fmt.Errorf("unexpected %s call", method)
+	}
+	f.results[method] = queue[1:]
+	f.params[method] = params
+	b, err := json.Marshal(queue[0])
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func rpcAccount(lamports uint64, owner solana.PublicKey, data []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"lamports": lamports,
+		"owner":    owner.String(),
+		"data":     []string{base64.StdEncoding.EncodeToString(data), "base64"},
+	}
+}
+
+func splTokenAccount(mint, owner solana.PublicKey, amount uint64) map[string]interface{} {
+	data := make([]byte, 165)
+	copy(data, mint.Bytes())
+	copy(data[32:], owner.Bytes())
+	binary.LittleEndian.PutUint64(data[64:], amount)
+	data[108] = 1 // initialized
+	return rpcAccount(2_039_280, solana.TokenProgramID, data)
+}
+
+func splMint(decimals uint8) map[string]interface{} {
+	data := make([]byte, 82)
+	data[44] = decimals
+	return rpcAccount(1_461_600, solana.TokenProgramID, data)
+}
+
+func parsedTokenTransfer(source, destination, authority solana.PublicKey, amount string) map[string]interface{} {
+	return map[string]interface{}{
+		"program":   "spl-token",
+		"programId": solana.TokenProgramID.String(),
+		"parsed": map[string]interface{}{
+			"type": "transfer",
+			"info": map[string]interface{}{
+				"source":      source.String(),
+				"destination": destination.String(),
+				"authority":   authority.String(),
+				"amount":      amount,
+			},
+		},
+		"stackHeight": 2,
+	}
+}
+
+// newSimulatedRaydiumSwap is an unsigned WSOL → BONK Raydium V4 swap and a node
+// that simulates it (or fails it with simErr).
+func newSimulatedRaydiumSwap(simErr interface{}) (*solana.Transaction, *fakeRPC) {
+	sol, bonk := NATIVE_SOL_MINT_PROGRAM_ID, solana.MustPublicKeyFromBase58(testBonkMint)
+	userSOL, userBonk, vaultSOL, vaultBonk, amm, authority := testKey(1), testKey(2), testKey(3), testKey(4), testKey(5), testKey(6)
+	tx := &solana.Transaction{Message: solana.Message{
+		Header:      solana.MessageHeader{NumRequiredSignatures: 1, NumReadonlyUnsignedAccounts: 3},
+		AccountKeys: []solana.PublicKey{testPayer, userSOL, userBonk, vaultSOL, vaultBonk, amm, authority, solana.TokenProgramID, RAYDIUM_V4_PROGRAM_ID},
+		Instructions: []solana.CompiledInstruction{
+			{ProgramIDIndex: 8, Accounts: []uint16{7, 5, 6, 3, 4, 1, 2, 0}, Data: []byte{9}},
+		},
+	}}
+
+	pre := []interface{}{
+		rpcAccount(1_000_000_000, solana.SystemProgramID, nil),
+		splTokenAccount(sol, testPayer, 1_000_000),
+		splTokenAccount(bonk, testPayer, 0),
+		splTokenAccount(sol, authority, 5_000_000_000),
+		splTokenAccount(bonk, authority, 900_000_000),
+		rpcAccount(6_124_800, RAYDIUM_V4_PROGRAM_ID, make([]byte, 752)),
+	}
+	sim := map[string]interface{}{
+		"err":           simErr,
+		"unitsConsumed": 31_337,
+		"logs":          []string{"Program " + RAYDIUM_V4_PROGRAM_ID.String() + " invoke [1]"},
+	}
+	if simErr == nil {
+		sim["accounts"] = []interface{}{
+			rpcAccount(999_995_000, solana.SystemProgramID, nil),
+			splTokenAccount(sol, testPayer, 0),
+			splTokenAccount(bonk, testPayer, 42_000),
+			splTokenAccount(sol, authority, 5_001_000_000),
+			splTokenAccount(bonk, authority, 899_958_000),
+			pre[5],
+		}
+		// inner instructions come back jsonParsed, as from a real node
+		sim["innerInstructions"] = []interface{}{map[string]interface{}{
+			"index": 0,
+			"instructions": []interface{}{
+				parsedTokenTransfer(userSOL, vaultSOL, testPayer, "1000000"),
+				parsedTokenTransfer(vaultBonk, userBonk, authority, "42000"),
+			},
+		}}
+	} else {
+		sim["logs"] = append(sim["logs"].([]string), "Program "+RAYDIUM_V4_PROGRAM_ID.String()+" failed: custom program error: 0x1e")
+	}
+
+	return tx, &fakeRPC{
+		results: map[string][]interface{}{
+			"getMultipleAccounts": {
+				map[string]interface{}{"context": map[string]interface{}{"slot": 300}, "value": pre},
+				map[string]interface{}{"context": map[string]interface{}{"slot": 300}, "value": []interface{}{splMint(9), splMint(5)}},
+			},
+			"simulateTransaction": {map[string]interface{}{"context": map[string]interface{}{"slot": 300}, "value": sim}},
+		},
+		params: map[string][]interface{}{},
+	}
+}
+
+func TestSimulateAndParse(t *testing.T) {
+	tx, node := newSimulatedRaydiumSwap(nil)
+	res, err := SimulateAndParse(context.Background(), node, tx)
+	if err != nil {
+		t.Fatalf("SimulateAndParse: %v", err)
+	}
+	if res.Swap == nil {
+		t.Fatal("no swap")
+	}
+	s := res.Swap
+	if !s.TokenInMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || s.TokenInAmount != 1_000_000 || s.TokenOutAmount != 42_000 || s.TokenOutDecimals != 5 {
+		t.Errorf("swap = %s %d → %s %d (%d decimals)", s.TokenInMint, s.TokenInAmount, s.TokenOutMint, s.TokenOutAmount, s.TokenOutDecimals)
+	}
+	if res.Slot != 300 || s.Slot != 300 || res.UnitsConsumed != 31_337 || s.Fees.NetworkFee != 5000 {
+		t.Errorf("slot %d/%d, units %d, fee %d", res.Slot, s.Slot, res.UnitsConsumed, s.Fees.NetworkFee)
+	}
+
+	opts := node.params["simulateTransaction"][1].(map[string]interface{})
+	if opts["innerInstructions"] != true || opts["sigVerify"] != false || opts["replaceRecentBlockhash"] != true || opts["minContextSlot"] != uint64(300) {
+		t.Errorf("simulate options = %v", opts)
+	}
+	// writable accounts only: payer, both user accounts, both vaults, the pool
+	if addrs := opts["accounts"].(map[string]interface{})["addresses"].([]solana.PublicKey); len(addrs) != 6 {
+		t.Errorf("snapshot addresses = %v", addrs)
+	}
+}
+
+func TestSimulateAndParse_Failed(t *testing.T) {
+	tx, node := newSimulatedRaydiumSwap(map[string]interface{}{"InstructionError": []interface{}{0, map[string]interface{}{"Custom": 30}}})
+	res, err := SimulateAndParse(context.Background(), node, tx)
+	if err != nil {
+		t.Fatalf("SimulateAndParse: %v", err)
+	}
+	if res.Swap != nil || res.Failed == nil {
+		t.Fatalf("swap = %+v, failed = %+v", res.Swap, res.Failed)
+	}
+	if f := res.Failed; f.Reason != FailureSlippage || f.ErrorCode == nil || *f.ErrorCode != 30 {
+		t.Errorf("failed = %+v", res.Failed)
+	}
+}
+
+func TestSimulateAndParse_SlotMismatch(t *testing.T) {
+	tx, node := newSimulatedRaydiumSwap(nil)
+	pre := node.results["getMultipleAccounts"][0]
+	sim := node.results["simulateTransaction"][0].(map[string]interface{})
+	moved := map[string]interface{}{"context": map[string]interface{}{"slot": 301}, "value": sim["value"]}
+	node.results["getMultipleAccounts"] = []interface{}{pre, pre, pre}
+	node.results["simulateTransaction"] = []interface{}{moved, moved, moved}
+
+	if _, err := SimulateAndParse(context.Background(), node, tx); err == nil {
+		t.Fatal("SimulateAndParse accepted a simulation from another slot")
+	}
+	if n := len(node.results["simulateTransaction"]); n != 3-maxSimulationAttempts {
+		t.Errorf("%d simulations left, want %d", n, 3-maxSimulationAttempts)
+	}
+}
+
+func TestSimulateAndParse_TooManyAccounts(t *testing.T) {
+	tx, node := newSimulatedRaydiumSwap(nil)
+	for i := 0; i < maxSimulationAccounts; i++ {
+		tx.Message.AccountKeys = append([]solana.PublicKey{testPayer}, tx.Message.AccountKeys...)
+	}
+	if _, err := SimulateAndParse(context.Background(), node, tx); err == nil {
+		t.Fatal("SimulateAndParse dropped accounts silently")
+	}
+}
+
+func TestSimulatedInstruction_Compile(t *testing.T) {
+	mint, source, destination := testKey(1), testKey(2), testKey(3)
+	keys := []solana.PublicKey{testPayer, source, destination, mint, solana.TokenProgramID, solana.SystemProgramID, JUPITER_PROGRAM_ID}
+	index := make(map[solana.PublicKey]uint16, len(keys))
+	for i, k := range keys {
+		index[k] = uint16(i)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		raw      string
+		program  uint16
+		accounts []uint16
+		data     []byte
+	}{
+		{"compiled", `{"programIdIndex":4,"accounts":[1,2,0],"data":"` + base58.Encode(transferData(7)) + `","stackHeight":2}`,
+			4, []uint16{1, 2, 0}, transferData(7)},
+		{"partially decoded", `{"programId":"` + JUPITER_PROGRAM_ID.String() + `","accounts":["` + testPayer.String() + `"],"data":"` + base58.Encode([]byte{1, 2}) + `","stackHeight":2}`,
+			6, []uint16{0}, []byte{1, 2}},
+		{"transferChecked", `{"program":"spl-token","programId":"` + solana.TokenProgramID.String() + `","parsed":{"type":"transferChecked","info":{"source":"` + source.String() + `","mint":"` + mint.String() + `","destination":"` + destination.String() + `","authority":"` + testPayer.String() + `","tokenAmount":{"amount":"7","decimals":6}}}}`,
+			4, []uint16{1, 3, 2, 0}, append(append([]byte{12}, transferData(7)[1:]...), 6)},
+		{"system transfer", `{"program":"system","programId":"` + solana.SystemProgramID.String() + `","parsed":{"type":"transfer","info":{"source":"` + testPayer.String() + `","destination":"` + destination.String() + `","lamports":7}}}`,
+			5, []uint16{0, 2}, []byte{2, 0, 0, 0, 7, 0, 0, 0, 0, 0, 0, 0}},
+		{"other parsed", `{"program":"spl-token","programId":"` + solana.TokenProgramID.String() + `","parsed":{"type":"initializeImmutableOwner","info":{"account":"` + destination.String() + `"}}}`,
+			4, nil, nil},
+	} {
+		var ri simulatedInstruction
+		if err := json.Unmarshal([]byte(tc.raw), &ri); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		ci, err := ri.compile(index)
+		if err != nil {
+			t.Fatalf("%s: compile: %v", tc.name, err)
+		}
+		if ci.ProgramIDIndex != tc.program || fmt.Sprint(ci.Accounts) != fmt.Sprint(tc.accounts) || !bytes.Equal(ci.Data, tc.data) {
+			t.Errorf("%s: got %d %v %v", tc.name, ci.ProgramIDIndex, ci.Accounts, []byte(ci.Data))
+		}
+	}
+
+	var ri simulatedInstruction
+	_ = json.Unmarshal([]byte(`{"programId":"`+testKey(9).String()+`","accounts":[],"data":""}`), &ri)
+	if _, err := ri.compile(index); err == nil {
+		t.Error("unknown program accepted")
+	}
+}
@@ -0,0 +1,42 @@
+package solanaswapgo
+
+import "math"
+
+// applySwapArgs attaches the declared arguments of the swap's instruction and
+// measures the executed amounts against them. Slippage is only computed when
+// the declared mints match the resolved swap (a route's args describe the
+// whole route, a batched instruction's may not).
+func (p *Parser) applySwapArgs(swapInfo *SwapInfo) {
+	args := p.swapArgsFor(swapInfo.InstructionIndex)
+	swapInfo.SwapArgs = args
+	if args == nil {
+		return
+	}
+	if (!args.InputMint.IsZero() && !args.InputMint.Equals(swapInfo.TokenInMint)) ||
+		(!args.OutputMint.IsZero() && !args.OutputMint.Equals(swapInfo.TokenOutMint)) {
+		return
+	}
+
+	if args.ExactOut {
+		// paid more than quoted / room left under max_in
+		if args.QuotedAmountIn > 0 {
+			swapInfo.SlippageBps = bpsPtr(float64(swapInfo.TokenInAmount)-float64(args.QuotedAmountIn), float64(args.QuotedAmountIn))
+		}
+		if args.MaxAmountIn > 0 && swapInfo.TokenInAmount > 0 {
+			swapInfo.SlippageMarginBps = bpsPtr(float64(args.MaxAmountIn)-float64(swapInfo.TokenInAmount), float64(swapInfo.TokenInAmount))
+		}
+		return
+	}
+	// received less than quoted / room left above min_out
+	if args.QuotedAmountOut > 0 {
+		swapInfo.SlippageBps = bpsPtr(float64(args.QuotedAmountOut)-float64(swapInfo.TokenOutAmount), float64(args.QuotedAmountOut))
+	}
+	if args.MinAmountOut > 0 && swapInfo.TokenOutAmount > 0 {
+		swapInfo.SlippageMarginBps = bpsPtr(float64(swapInfo.TokenOutAmount)-float64(args.MinAmountOut), float64(swapInfo.TokenOutAmount))
+	}
+}
+
+func bpsPtr(diff, base float64) *int64 {
+	v := int64(math.Round(diff * 10_000 / base))
+	return &v
+}
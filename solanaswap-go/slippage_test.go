@@ -0,0 +1,48 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestProcessSwapData_SlippageMargin(t *testing.T) {
+	keys, swap := raydiumV4SwapBaseIn(1_000_000, 40_000)
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+	meta := &rpc.TransactionMeta{
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []rpc.CompiledInstruction{
+				{ProgramIDIndex: 18, Accounts: []uint16{15, 5, 0}, Data: transferData(1_000_000)},
+				{ProgramIDIndex: 18, Accounts: []uint16{6, 16, 2}, Data: transferData(42_000)},
+			},
+		}},
+		PostTokenBalances: []rpc.TokenBalance{
+			tokenBalance(5, sol, testKey(2), "1000000", 9),
+			tokenBalance(6, testBonkMint, testKey(2), "0", 5),
+			tokenBalance(15, sol, testPayer, "0", 9),
+			tokenBalance(16, testBonkMint, testPayer, "42000", 5),
+		},
+	}
+	p := newTestParser(t, keys, []solana.CompiledInstruction{swap}, meta)
+
+	swaps, err := p.ParseTransaction()
+	if err != nil {
+		t.Fatalf("ParseTransaction: %v", err)
+	}
+	info, err := p.ProcessSwapData(swaps)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if info.SwapArgs == nil || info.SwapArgs.MinAmountOut != 40_000 {
+		t.Fatalf("SwapArgs = %+v", info.SwapArgs)
+	}
+	if info.SlippageBps != nil {
+		t.Errorf("SlippageBps = %d, want nil (no quote in a Raydium instruction)", *info.SlippageBps)
+	}
+	// (42000 - 40000) / 42000 = 476.19 bps
+	if info.SlippageMarginBps == nil || *info.SlippageMarginBps != 476 {
+		t.Errorf("SlippageMarginBps = %v, want 476", info.SlippageMarginBps)
+	}
+}
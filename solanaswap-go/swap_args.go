@@ -0,0 +1,459 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// SwapArgs is what the user declared in a swap instruction, before execution.
+//
+// Exact-in swaps fill AmountIn and MinAmountOut; exact-out swaps set ExactOut
+// and fill AmountOut and MaxAmountIn. Mints are zero when they can't be
+// resolved from the instruction accounts or the token balances.
+type SwapArgs struct {
+	ProgramID solana.PublicKey
+
+	InputMint  solana.PublicKey
+	OutputMint solana.PublicKey
+
+	ExactOut     bool
+	AmountIn     uint64
+	MinAmountOut uint64
+	AmountOut    uint64
+	MaxAmountIn  uint64
+
+	// SlippageBps is the tolerance the user declared, for venues that take one
+	// (Jupiter, Moonshot); MinAmountOut/MaxAmountIn are derived from it there.
+	SlippageBps uint16
+	// QuotedAmountOut (exact-in) / QuotedAmountIn (exact-out) is the quote the
+	// tolerance applies to, when the instruction carries one.
+	QuotedAmountOut uint64
+	QuotedAmountIn  uint64
+}
+
+var (
+	raydiumCPMMSwapBaseInputDisc  = anchorDiscriminator8("swap_base_input")
+	raydiumCPMMSwapBaseOutputDisc = anchorDiscriminator8("swap_base_output")
+	anchorSwapDisc                = anchorDiscriminator8("swap")
+	anchorSwapV2Disc              = anchorDiscriminator8("swap_v2")
+	meteoraSwap2Disc              = anchorDiscriminator8("swap2")
+	meteoraSwapExactOutDisc       = anchorDiscriminator8("swap_exact_out")
+	meteoraSwapExactOut2Disc      = anchorDiscriminator8("swap_exact_out2")
+	pumpBuyDisc                   = anchorDiscriminator8("buy")
+	pumpSellDisc                  = anchorDiscriminator8("sell")
+	pumpAMMBuyExactQuoteInDisc    = anchorDiscriminator8("buy_exact_quote_in")
+
+	jupiterRouteDisc                       = anchorDiscriminator8("route")
+	jupiterSharedAccountsRouteDisc         = anchorDiscriminator8("shared_accounts_route")
+	jupiterExactOutRouteDisc               = anchorDiscriminator8("exact_out_route")
+	jupiterSharedAccountsExactOutRouteDisc = anchorDiscriminator8("shared_accounts_exact_out_route")
+)
+
+// DecodeSwapArgs decodes the declared arguments of a swap instruction. It
+// returns false for programs or instructions it doesn't know.
+func (p *Parser) DecodeSwapArgs(inst solana.CompiledInstruction) (*SwapArgs, bool) {
+	if int(inst.ProgramIDIndex) >= len(p.allAccountKeys) {
+		return nil, false
+	}
+	progID := p.allAccountKeys[inst.ProgramIDIndex]
+	var args *SwapArgs
+	switch {
+	case progID.Equals(RAYDIUM_V4_PROGRAM_ID):
+		args = p.raydiumV4SwapArgs(inst)
+	case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID):
+		args = p.raydiumCPMMSwapArgs(inst)
+	case progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
+		args = p.raydiumCLMMSwapArgs(inst)
+	case progID.Equals(ORCA_PROGRAM_ID):
+		args = p.orcaSwapArgs(inst)
+	case progID.Equals(METEORA_PROGRAM_ID):
+		args = p.meteoraDLMMSwapArgs(inst)
+	case progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
+		args = p.pumpAMMSwapArgs(inst)
+	case progID.Equals(PUMP_FUN_PROGRAM_ID):
+		args = p.pumpfunSwapArgs(inst)
+	case progID.Equals(MOONSHOT_PROGRAM_ID):
+		args = p.moonshotSwapArgs(inst)
+	case progID.Equals(JUPITER_PROGRAM_ID):
+		args = p.jupiterSwapArgs(inst)
+	case progID.Equals(OKX_DEX_ROUTER_PROGRAM_ID):
+		args = p.okxSwapArgs(inst)
+	}
+	if args == nil {
+		return nil, false
+	}
+	args.ProgramID = progID
+	return args, true
+}
+
+// swapArgsFor decodes the arguments of the swap carried by the outer instruction
+// at index, looking at its CPIs when the outer program is a wrapper (bots).
+func (p *Parser) swapArgsFor(index int) *SwapArgs {
+	if index < 0 || index >= len(p.txInfo.Message.Instructions) {
+		return nil
+	}
+	if args, ok := p.DecodeSwapArgs(p.txInfo.Message.Instructions[index]); ok {
+		return args
+	}
+	for _, inst := range p.getInnerInstructions(index) {
+		if args, ok := p.DecodeSwapArgs(inst); ok {
+			return args
+		}
+	}
+	return nil
+}
+
+// Raydium V4: swapBaseIn (9) amount_in/minimum_amount_out, swapBaseOut (11)
+// max_amount_in/amount_out. The user source/destination accounts are always
+// third and second to last; pool vaults sit 13/12 from the end.
+func (p *Parser) raydiumV4SwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	data, n := inst.Data, len(inst.Accounts)
+	if len(data) < 17 || n < 17 {
+		return nil
+	}
+	a, _ := readU64(data, 1)
+	b, _ := readU64(data, 9)
+	args := &SwapArgs{}
+	switch data[0] {
+	case 9:
+		args.AmountIn, args.MinAmountOut = a, b
+	case 11:
+		args.ExactOut, args.MaxAmountIn, args.AmountOut = true, a, b
+	default:
+		return nil
+	}
+	args.InputMint, args.OutputMint = p.resolveSwapMints(
+		inst.Accounts[n-3], inst.Accounts[n-2], inst.Accounts[n-13], inst.Accounts[n-12])
+	return args
+}
+
+// Raydium CPMM: both mints are passed explicitly (accounts 10 and 11).
+func (p *Parser) raydiumCPMMSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 24 || len(inst.Accounts) < 12 {
+		return nil
+	}
+	a, _ := readU64(inst.Data, 8)
+	b, _ := readU64(inst.Data, 16)
+	args := &SwapArgs{
+		InputMint:  p.instructionAccount(inst, 10),
+		OutputMint: p.instructionAccount(inst, 11),
+	}
+	switch disc {
+	case raydiumCPMMSwapBaseInputDisc:
+		args.AmountIn, args.MinAmountOut = a, b
+	case raydiumCPMMSwapBaseOutputDisc:
+		args.ExactOut, args.MaxAmountIn, args.AmountOut = true, a, b
+	default:
+		return nil
+	}
+	return args
+}
+
+// Raydium CLMM swap / swap_v2: amount, other_amount_threshold, sqrt_price_limit_x64, is_base_input.
+func (p *Parser) raydiumCLMMSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 41 || len(inst.Accounts) < 7 {
+		return nil
+	}
+	if disc != anchorSwapDisc && disc != anchorSwapV2Disc {
+		return nil
+	}
+	amount, _ := readU64(inst.Data, 8)
+	threshold, _ := readU64(inst.Data, 16)
+	args := &SwapArgs{}
+	if inst.Data[40] != 0 {
+		args.AmountIn, args.MinAmountOut = amount, threshold
+	} else {
+		args.ExactOut, args.AmountOut, args.MaxAmountIn = true, amount, threshold
+	}
+	if disc == anchorSwapV2Disc && len(inst.Accounts) >= 13 {
+		args.InputMint = p.instructionAccount(inst, 11)
+		args.OutputMint = p.instructionAccount(inst, 12)
+	} else {
+		// input_vault / output_vault always show up in the token balances
+		args.InputMint, _ = p.tokenAccountMint(inst.Accounts[5])
+		args.OutputMint, _ = p.tokenAccountMint(inst.Accounts[6])
+	}
+	return args
+}
+
+// Orca Whirlpool swap / swap_v2: amount, other_amount_threshold, sqrt_price_limit,
+// amount_specified_is_input, a_to_b.
+func (p *Parser) orcaSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 42 {
+		return nil
+	}
+	var mintA, mintB solana.PublicKey
+	switch {
+	case disc == anchorSwapDisc && len(inst.Accounts) >= 7:
+		mintA, _ = p.tokenAccountMint(inst.Accounts[4]) // token_vault_a
+		mintB, _ = p.tokenAccountMint(inst.Accounts[6]) // token_vault_b
+	case disc == orcaSwapV2Disc && len(inst.Accounts) >= 7:
+		mintA = p.instructionAccount(inst, 5)
+		mintB = p.instructionAccount(inst, 6)
+	default:
+		return nil
+	}
+	amount, _ := readU64(inst.Data, 8)
+	threshold, _ := readU64(inst.Data, 16)
+	args := &SwapArgs{InputMint: mintA, OutputMint: mintB}
+	if inst.Data[41] == 0 { // b → a
+		args.InputMint, args.OutputMint = mintB, mintA
+	}
+	if inst.Data[40] != 0 {
+		args.AmountIn, args.MinAmountOut = amount, threshold
+	} else {
+		args.ExactOut, args.AmountOut, args.MaxAmountIn = true, amount, threshold
+	}
+	return args
+}
+
+// Meteora DLMM swap/swap2 (amount_in, min_amount_out) and
+// swap_exact_out/swap_exact_out2 (max_in_amount, out_amount).
+func (p *Parser) meteoraDLMMSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 24 || len(inst.Accounts) < 8 {
+		return nil
+	}
+	a, _ := readU64(inst.Data, 8)
+	b, _ := readU64(inst.Data, 16)
+	args := &SwapArgs{}
+	switch disc {
+	case anchorSwapDisc, meteoraSwap2Disc:
+		args.AmountIn, args.MinAmountOut = a, b
+	case meteoraSwapExactOutDisc, meteoraSwapExactOut2Disc:
+		args.ExactOut, args.MaxAmountIn, args.AmountOut = true, a, b
+	default:
+		return nil
+	}
+	// user_token_in / user_token_out, falling back on the pair's reserves
+	args.InputMint, args.OutputMint = p.resolveSwapMints(
+		inst.Accounts[4], inst.Accounts[5], inst.Accounts[2], inst.Accounts[3])
+	return args
+}
+
+// PumpSwap: buy (base_amount_out, max_quote_amount_in), buy_exact_quote_in
+// (spendable_quote_in, min_base_amount_out), sell (base_amount_in, min_quote_amount_out).
+func (p *Parser) pumpAMMSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 24 || len(inst.Accounts) < 5 {
+		return nil
+	}
+	a, _ := readU64(inst.Data, 8)
+	b, _ := readU64(inst.Data, 16)
+	base := p.instructionAccount(inst, 3)
+	quote := p.instructionAccount(inst, 4)
+	switch disc {
+	case pumpBuyDisc:
+		return &SwapArgs{InputMint: quote, OutputMint: base, ExactOut: true, AmountOut: a, MaxAmountIn: b}
+	case pumpAMMBuyExactQuoteInDisc:
+		return &SwapArgs{InputMint: quote, OutputMint: base, AmountIn: a, MinAmountOut: b}
+	case pumpSellDisc:
+		return &SwapArgs{InputMint: base, OutputMint: quote, AmountIn: a, MinAmountOut: b}
+	}
+	return nil
+}
+
+// Pump.fun bonding curve: buy (amount, max_sol_cost), sell (amount, min_sol_output).
+func (p *Parser) pumpfunSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 24 || len(inst.Accounts) < 3 {
+		return nil
+	}
+	a, _ := readU64(inst.Data, 8)
+	b, _ := readU64(inst.Data, 16)
+	mint := p.instructionAccount(inst, 2)
+	switch disc {
+	case pumpBuyDisc:
+		return &SwapArgs{InputMint: NATIVE_SOL_MINT_PROGRAM_ID, OutputMint: mint, ExactOut: true, AmountOut: a, MaxAmountIn: b}
+	case pumpSellDisc:
+		return &SwapArgs{InputMint: mint, OutputMint: NATIVE_SOL_MINT_PROGRAM_ID, AmountIn: a, MinAmountOut: b}
+	}
+	return nil
+}
+
+// Moonshot buy/sell: token_amount, collateral_amount, fixed_side, slippage_bps.
+// The collateral amount is the quote; the bound is derived from slippage_bps.
+func (p *Parser) moonshotSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 33 || len(inst.Accounts) < 7 {
+		return nil
+	}
+	tokens, _ := readU64(inst.Data, 8)
+	collateral, _ := readU64(inst.Data, 16)
+	slippage, _ := readU64(inst.Data, 25)
+	bps := uint16(min(slippage, 10_000))
+	mint := p.instructionAccount(inst, 6)
+	switch disc {
+	case [8]byte(MOONSHOT_BUY_INSTRUCTION):
+		return &SwapArgs{
+			InputMint: NATIVE_SOL_MINT_PROGRAM_ID, OutputMint: mint,
+			ExactOut: true, AmountOut: tokens, MaxAmountIn: withSlippageUp(collateral, bps),
+			SlippageBps: bps, QuotedAmountIn: collateral,
+		}
+	case [8]byte(MOONSHOT_SELL_INSTRUCTION):
+		return &SwapArgs{
+			InputMint: mint, OutputMint: NATIVE_SOL_MINT_PROGRAM_ID,
+			AmountIn: tokens, MinAmountOut: withSlippageDown(collateral, bps),
+			SlippageBps: bps, QuotedAmountOut: collateral,
+		}
+	}
+	return nil
+}
+
+// Jupiter v6 routes end with in_amount, quoted_out_amount, slippage_bps,
+// platform_fee_bps (exact-out routes: out_amount, quoted_in_amount, ...).
+func (p *Parser) jupiterSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 8+19 {
+		return nil
+	}
+	tail := inst.Data[len(inst.Data)-19:]
+	amount, _ := readU64(tail, 0)
+	quoted, _ := readU64(tail, 8)
+	bps := binary.LittleEndian.Uint16(tail[16:18])
+
+	var srcMintIdx, dstMintIdx, srcAccountIdx int = -1, -1, -1
+	exactOut := false
+	switch disc {
+	case jupiterRouteDisc:
+		srcAccountIdx, dstMintIdx = 2, 5
+	case jupiterSharedAccountsRouteDisc:
+		srcMintIdx, dstMintIdx = 7, 8
+	case jupiterExactOutRouteDisc:
+		srcMintIdx, dstMintIdx, exactOut = 5, 6, true
+	case jupiterSharedAccountsExactOutRouteDisc:
+		srcMintIdx, dstMintIdx, exactOut = 7, 8, true
+	default:
+		return nil
+	}
+	if len(inst.Accounts) <= max(srcMintIdx, dstMintIdx, srcAccountIdx) {
+		return nil
+	}
+
+	args := &SwapArgs{SlippageBps: bps, OutputMint: p.instructionAccount(inst, dstMintIdx)}
+	if srcMintIdx >= 0 {
+		args.InputMint = p.instructionAccount(inst, srcMintIdx)
+	} else if mint, ok := p.tokenAccountMint(inst.Accounts[srcAccountIdx]); ok {
+		args.InputMint = mint
+	}
+	if exactOut {
+		args.ExactOut, args.AmountOut, args.MaxAmountIn = true, amount, withSlippageUp(quoted, bps)
+		args.QuotedAmountIn = quoted
+	} else {
+		args.AmountIn, args.MinAmountOut = amount, withSlippageDown(quoted, bps)
+		args.QuotedAmountOut = quoted
+	}
+	return args
+}
+
+// OKX router swap/swap2/commission_spl_swap2 start with SwapArgs
+// {amount_in, expect_amount_out, min_return, ...}; mints are accounts 3 and 4.
+func (p *Parser) okxSwapArgs(inst solana.CompiledInstruction) *SwapArgs {
+	disc, ok := prefix8(inst.Data)
+	if !ok || len(inst.Data) < 32 || len(inst.Accounts) < 5 {
+		return nil
+	}
+	switch disc {
+	case OKX_SWAP_DISCRIMINATOR, OKX_SWAP2_DISCRIMINATOR, OKX_COMMISSION_SPL_SWAP2_DISCRIMINATOR:
+	default:
+		return nil
+	}
+	amountIn, _ := readU64(inst.Data, 8)
+	minReturn, _ := readU64(inst.Data, 24)
+	return &SwapArgs{
+		InputMint:    p.instructionAccount(inst, 3),
+		OutputMint:   p.instructionAccount(inst, 4),
+		AmountIn:     amountIn,
+		MinAmountOut: minReturn,
+	}
+}
+
+// resolveSwapMints finds the input/output mints from the user's token accounts,
+// using the pool's two vaults to fill in a side that isn't in the token balances
+// (e.g. a WSOL account opened and closed within the transaction).
+func (p *Parser) resolveSwapMints(userIn, userOut, vaultA, vaultB uint16) (in, out solana.PublicKey) {
+	in, inOK := p.tokenAccountMint(userIn)
+	out, outOK := p.tokenAccountMint(userOut)
+	if inOK && outOK {
+		return in, out
+	}
+	a, aOK := p.tokenAccountMint(vaultA)
+	b, bOK := p.tokenAccountMint(vaultB)
+	if !aOK || !bOK {
+		return in, out
+	}
+	other := func(m solana.PublicKey) solana.PublicKey {
+		if m.Equals(a) {
+			return b
+		}
+		return a
+	}
+	switch {
+	case inOK:
+		out = other(in)
+	case outOK:
+		in = other(out)
+	}
+	return in, out
+}
+
+// instructionAccount returns the key at position pos of inst's accounts, or
+// the zero key when it is out of range or not resolved.
+func (p *Parser) instructionAccount(inst solana.CompiledInstruction, pos int) solana.PublicKey {
+	if pos < 0 || pos >= len(inst.Accounts) || int(inst.Accounts[pos]) >= len(p.allAccountKeys) {
+		return solana.PublicKey{}
+	}
+	return p.allAccountKeys[inst.Accounts[pos]]
+}
+
+// tokenAccountMint looks up the mint of the token account at index from the pre/post token balances.
+func (p *Parser) tokenAccountMint(index uint16) (solana.PublicKey, bool) {
+	for _, balances := range [][]rpc.TokenBalance{p.txMeta.PostTokenBalances, p.txMeta.PreTokenBalances} {
+		for _, b := range balances {
+			if b.AccountIndex == index && !b.Mint.IsZero() {
+				return b.Mint, true
+			}
+		}
+	}
+	return solana.PublicKey{}, false
+}
+
+func prefix8(data []byte) ([8]byte, bool) {
+	var out [8]byte
+	if len(data) < 8 {
+		return out, false
+	}
+	copy(out[:], data[:8])
+	return out, true
+}
+
+func readU64(data []byte, offset int) (uint64, bool) {
+	if offset < 0 || len(data) < offset+8 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(data[offset : offset+8]), true
+}
+
+func withSlippageDown(amount uint64, bps uint16) uint64 {
+	return mulDiv(amount, 10_000-uint64(min(bps, 10_000)), 10_000)
+}
+
+func withSlippageUp(amount uint64, bps uint16) uint64 {
+	return mulDiv(amount, 10_000+uint64(bps), 10_000)
+}
+
+// mulDiv computes a*b/c without overflowing the intermediate product.
+func mulDiv(a, b, c uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi >= c {
+		return ^uint64(0)
+	}
+	q, _ := bits.Div64(hi, lo, c)
+	return q
+}
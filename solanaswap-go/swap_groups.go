@@ -0,0 +1,191 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ProcessAllSwaps resolves every distinct swap in the transaction.
+//
+// Legs are grouped by the outer instruction they were decoded under and, inside
+// one instruction, by the trader they belong to. Each group becomes its own
+// SwapInfo, in instruction order; groups that don't resolve are dropped.
+func (p *Parser) ProcessAllSwaps(swapDatas []SwapData) ([]SwapInfo, error) {
+	if len(swapDatas) == 0 {
+		return nil, fmt.Errorf("no swap data provided")
+	}
+
+	var results []SwapInfo
+	for _, g := range p.groupSwapLegs(swapDatas) {
+		info, err := p.processSwapGroup(g.legs, g.trader, g.instructionIndex)
+		if err != nil {
+			p.Log.Debugf("instruction %d (trader %s): %s", g.instructionIndex, g.trader, err)
+			continue
+		}
+		results = append(results, *info)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no valid swaps found")
+	}
+	return results, nil
+}
+
+type swapGroup struct {
+	instructionIndex int
+	trader           solana.PublicKey
+	legs             []SwapData
+}
+
+func (p *Parser) groupSwapLegs(swapDatas []SwapData) []swapGroup {
+	byIndex := make(map[int][]SwapData)
+	order := make([]int, 0)
+	for _, sd := range swapDatas {
+		if _, ok := byIndex[sd.InstructionIndex]; !ok {
+			order = append(order, sd.InstructionIndex)
+		}
+		byIndex[sd.InstructionIndex] = append(byIndex[sd.InstructionIndex], sd)
+	}
+	sort.Ints(order)
+
+	defaultTrader := p.resolveTrader()
+	var groups []swapGroup
+	for _, idx := range order {
+		legs := byIndex[idx]
+
+		legTraders := make([]solana.PublicKey, len(legs))
+		traders := make([]solana.PublicKey, 0)
+		for i, sd := range legs {
+			t := p.legTrader(sd)
+			legTraders[i] = t
+			if !t.IsZero() && !containsKey(traders, t) {
+				traders = append(traders, t)
+			}
+		}
+
+		if len(traders) <= 1 {
+			trader := defaultTrader
+			if len(traders) == 1 {
+				trader = traders[0]
+			}
+			groups = append(groups, swapGroup{instructionIndex: idx, trader: trader, legs: legs})
+			continue
+		}
+
+		// Several traders under one instruction (batched swaps): legs without an
+		// obvious owner go to the trader receiving them, else to the trader of the
+		// preceding leg (legs come in user→pool, pool→user order).
+		local := make([]swapGroup, len(traders))
+		pos := make(map[solana.PublicKey]int, len(traders))
+		for i, t := range traders {
+			local[i] = swapGroup{instructionIndex: idx, trader: t}
+			pos[t] = i
+		}
+		last := traders[0]
+		for i, sd := range legs {
+			t := legTraders[i]
+			if t.IsZero() {
+				if owner, ok := p.legDestinationOwner(sd); ok {
+					if _, known := pos[owner]; known {
+						t = owner
+					}
+				}
+			}
+			if t.IsZero() {
+				t = last
+			}
+			last = t
+			local[pos[t]].legs = append(local[pos[t]].legs, sd)
+		}
+		groups = append(groups, local...)
+	}
+	return groups
+}
+
+// legTrader returns the trader a single leg can be attributed to, or the zero key.
+func (p *Parser) legTrader(sd SwapData) solana.PublicKey {
+	switch v := sd.Data.(type) {
+	case *PumpfunTradeEvent:
+		return v.User
+	case *BalanceDeltaSwap:
+		return v.Owner
+	case *PhoenixTradeEvent:
+		return v.Taker
+	case *PumpSwapTradeEvent:
+		return v.User
+	case *TransferData:
+		return p.transferTrader(v.Info.Authority, v.Info.Destination)
+	case *TransferCheck:
+		return p.transferTrader(v.Info.Authority, v.Info.Destination)
+	}
+	return solana.PublicKey{}
+}
+
+// transferTrader: a transfer belongs to a trader when a signer authorized it or
+// when it lands in a token account owned by a signer.
+func (p *Parser) transferTrader(authority, destination string) solana.PublicKey {
+	if pk, err := solana.PublicKeyFromBase58(authority); err == nil && p.isSigner(pk) {
+		return pk
+	}
+	if owner, ok := p.tokenAccountOwner(destination); ok && p.isSigner(owner) {
+		return owner
+	}
+	return solana.PublicKey{}
+}
+
+func (p *Parser) legDestinationOwner(sd SwapData) (solana.PublicKey, bool) {
+	switch v := sd.Data.(type) {
+	case *TransferData:
+		return p.tokenAccountOwner(v.Info.Destination)
+	case *TransferCheck:
+		return p.tokenAccountOwner(v.Info.Destination)
+	}
+	return solana.PublicKey{}, false
+}
+
+// isSigner reports whether pk signed the transaction.
+func (p *Parser) isSigner(pk solana.PublicKey) bool {
+	n := int(p.txInfo.Message.Header.NumRequiredSignatures)
+	for i, k := range p.txInfo.Message.AccountKeys {
+		if i >= n {
+			break
+		}
+		if k.Equals(pk) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenAccountOwner looks up the owner of a token account from the pre/post token balances.
+func (p *Parser) tokenAccountOwner(account string) (solana.PublicKey, bool) {
+	owner, ok := p.tokenOwnerMap[account]
+	return owner, ok
+}
+
+func (p *Parser) extractTokenOwners() {
+	owners := make(map[string]solana.PublicKey)
+	record := func(idx uint16, owner *solana.PublicKey) {
+		if owner == nil || int(idx) >= len(p.allAccountKeys) {
+			return
+		}
+		owners[p.allAccountKeys[idx].String()] = *owner
+	}
+	for _, b := range p.txMeta.PreTokenBalances {
+		record(b.AccountIndex, b.Owner)
+	}
+	for _, b := range p.txMeta.PostTokenBalances {
+		record(b.AccountIndex, b.Owner)
+	}
+	p.tokenOwnerMap = owners
+}
+
+func containsKey(keys []solana.PublicKey, k solana.PublicKey) bool {
+	for _, x := range keys {
+		if x.Equals(k) {
+			return true
+		}
+	}
+	return false
+}
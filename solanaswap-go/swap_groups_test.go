@@ -0,0 +1,73 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const testBonkMint = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+
+func testTransfer(index int, authority solana.PublicKey, mint string, amount uint64, decimals uint8) SwapData {
+	return SwapData{
+		Type: RAYDIUM,
+		Data: &TransferData{
+			Info: TransferInfo{
+				Amount:      amount,
+				Authority:   authority.String(),
+				Source:      "src",
+				Destination: "dst",
+			},
+			Type:     "transfer",
+			Mint:     mint,
+			Decimals: decimals,
+		},
+		InstructionIndex: index,
+	}
+}
+
+func TestProcessAllSwaps_SplitsByInstruction(t *testing.T) {
+	p := newTestParser(t,
+		[]solana.PublicKey{testPayer, RAYDIUM_V4_PROGRAM_ID},
+		[]solana.CompiledInstruction{{ProgramIDIndex: 1}, {ProgramIDIndex: 1}},
+		nil,
+	)
+	pool := testVenueProgramID
+	sol := NATIVE_SOL_MINT_PROGRAM_ID.String()
+
+	legs := []SwapData{
+		testTransfer(0, testPayer, sol, 1_000_000_000, 9),
+		testTransfer(0, pool, testBonkMint, 5_000_000, 5),
+		testTransfer(1, testPayer, sol, 2_000_000_000, 9),
+		testTransfer(1, pool, testBonkMint, 9_000_000, 5),
+	}
+
+	all, err := p.ProcessAllSwaps(legs)
+	if err != nil {
+		t.Fatalf("ProcessAllSwaps: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("got %d swaps, want 2", len(all))
+	}
+	for i, want := range []struct{ in, out uint64 }{{1_000_000_000, 5_000_000}, {2_000_000_000, 9_000_000}} {
+		si := all[i]
+		if si.InstructionIndex != i {
+			t.Errorf("swap %d: InstructionIndex=%d", i, si.InstructionIndex)
+		}
+		if !si.TokenInMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || si.TokenInAmount != want.in {
+			t.Errorf("swap %d: in=%s/%d", i, si.TokenInMint, si.TokenInAmount)
+		}
+		if si.TokenOutMint.String() != testBonkMint || si.TokenOutAmount != want.out {
+			t.Errorf("swap %d: out=%s/%d", i, si.TokenOutMint, si.TokenOutAmount)
+		}
+	}
+
+	// The convenience wrapper still collapses everything into one result.
+	one, err := p.ProcessSwapData(legs)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %v", err)
+	}
+	if one.TokenInAmount != 3_000_000_000 {
+		t.Errorf("collapsed TokenInAmount=%d, want 3000000000", one.TokenInAmount)
+	}
+}
@@ -0,0 +1,133 @@
+package solanaswapgo
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// FeePayer returns the account that paid the transaction fee.
+func (p *Parser) FeePayer() solana.PublicKey {
+	return p.txInfo.Message.AccountKeys[0]
+}
+
+// resolveTrader finds whose swap this is from the balance changes: the trader
+// gave up one asset and received another, net. Token balances are summed per
+// owner and mint, and an owner's own lamports count as SOL (fee added back for
+// the fee payer), so wrapping and unwrapping nets out. Pools and bonding curves
+// show the same pattern, so among the owners that gave and got, signers come
+// first (co-signers before the fee payer, who may be a relayer paid in tokens),
+// then wallets (on-curve keys, e.g. bot custody), then program accounts such as
+// a DCA vault. Falls back to the fee payer. The result is computed once.
+func (p *Parser) resolveTrader() solana.PublicKey {
+	if p.trader == nil {
+		trader := p.computeTrader()
+		p.trader = &trader
+	}
+	return *p.trader
+}
+
+func (p *Parser) computeTrader() solana.PublicKey {
+	type flow struct{ in, out uint64 }
+	var order []solana.PublicKey
+	flows := make(map[solana.PublicKey]map[solana.PublicKey]*flow)
+	record := func(owner, mint solana.PublicKey, pre, post uint64) {
+		byMint, ok := flows[owner]
+		if !ok {
+			byMint = make(map[solana.PublicKey]*flow)
+			flows[owner] = byMint
+			order = append(order, owner)
+		}
+		f, ok := byMint[mint]
+		if !ok {
+			f = &flow{}
+			byMint[mint] = f
+		}
+		if post > pre {
+			f.in += post - pre
+		} else {
+			f.out += pre - post
+		}
+	}
+
+	type balance struct {
+		owner, mint solana.PublicKey
+		amount      uint64
+	}
+	pre := make(map[uint16]balance)
+	for _, b := range p.txMeta.PreTokenBalances {
+		if b.Owner == nil || b.UiTokenAmount == nil {
+			continue
+		}
+		amt, _ := strconv.ParseUint(b.UiTokenAmount.Amount, 10, 64)
+		pre[b.AccountIndex] = balance{*b.Owner, b.Mint, amt}
+	}
+	for _, b := range p.txMeta.PostTokenBalances {
+		if b.Owner == nil || b.UiTokenAmount == nil {
+			continue
+		}
+		amt, _ := strconv.ParseUint(b.UiTokenAmount.Amount, 10, 64)
+		record(*b.Owner, b.Mint, pre[b.AccountIndex].amount, amt)
+		delete(pre, b.AccountIndex)
+	}
+	// accounts closed during the transaction: only a pre balance
+	closed := make([]uint16, 0, len(pre))
+	for k := range pre {
+		closed = append(closed, k)
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i] < closed[j] })
+	for _, k := range closed {
+		record(pre[k].owner, pre[k].mint, pre[k].amount, 0)
+	}
+
+	for i, key := range p.allAccountKeys {
+		if _, tracked := flows[key]; !tracked || i >= len(p.txMeta.PreBalances) || i >= len(p.txMeta.PostBalances) {
+			continue
+		}
+		postLamports := p.txMeta.PostBalances[i]
+		if i == 0 {
+			postLamports += p.txMeta.Fee
+		}
+		record(key, NATIVE_SOL_MINT_PROGRAM_ID, p.txMeta.PreBalances[i], postLamports)
+	}
+
+	keys := p.txInfo.Message.AccountKeys
+	n := min(int(p.txInfo.Message.Header.NumRequiredSignatures), len(keys))
+	signerRank := make(map[solana.PublicKey]int, n)
+	for i := 1; i < n; i++ {
+		signerRank[keys[i]] = 0
+	}
+	if n > 0 {
+		signerRank[keys[0]] = 1
+	}
+	rank := func(owner solana.PublicKey) int {
+		if r, ok := signerRank[owner]; ok {
+			return r
+		}
+		if owner.IsOnCurve() {
+			return 2
+		}
+		return 3
+	}
+
+	var trader solana.PublicKey
+	best := -1
+	for _, owner := range order {
+		var gave, got bool
+		for _, f := range flows[owner] {
+			gave = gave || f.out > f.in
+			got = got || f.in > f.out
+		}
+		if !gave || !got {
+			continue
+		}
+		if r := rank(owner); best < 0 || r < best {
+			trader, best = owner, r
+		}
+	}
+	if best < 0 {
+		return p.FeePayer()
+	}
+	return trader
+}
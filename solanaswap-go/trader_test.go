@@ -0,0 +1,100 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestResolveTrader_RelayedSwap(t *testing.T) {
+	relayer, trader, pool := testPayer, testKey(1), testKey(2)
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	withPre := func(b rpc.TokenBalance, amount string) rpc.TokenBalance {
+		b.UiTokenAmount = &rpc.UiTokenAmount{Amount: amount, Decimals: b.UiTokenAmount.Decimals}
+		return b
+	}
+	post := []rpc.TokenBalance{
+		tokenBalance(2, usdc, trader, "0", 6),
+		tokenBalance(3, testBonkMint, trader, "500", 5),
+		tokenBalance(4, usdc, pool, "1100", 6),
+		tokenBalance(5, testBonkMint, pool, "500", 5),
+	}
+	pre := []rpc.TokenBalance{
+		withPre(post[0], "100"),
+		withPre(post[1], "0"),
+		withPre(post[2], "1000"),
+		withPre(post[3], "1000"),
+	}
+	p := newTestParser(t,
+		[]solana.PublicKey{relayer, trader, testKey(3), testKey(4), testKey(5), testKey(6)},
+		nil,
+		&rpc.TransactionMeta{
+			Fee:               5_000,
+			PreBalances:       []uint64{10_000_000, 1_000_000, 0, 0, 0, 0},
+			PostBalances:      []uint64{7_955_720, 1_000_000, 2_039_280, 0, 0, 0},
+			PreTokenBalances:  pre,
+			PostTokenBalances: post,
+		},
+	)
+	p.txInfo.Message.Header.NumRequiredSignatures = 2
+
+	if got := p.resolveTrader(); !got.Equals(trader) {
+		t.Errorf("resolveTrader = %s, want %s", got, trader)
+	}
+	if got := p.FeePayer(); !got.Equals(relayer) {
+		t.Errorf("FeePayer = %s, want %s", got, relayer)
+	}
+}
+
+// curveKey returns the first test key at or after b that is (or isn't) a
+// valid ed25519 point, i.e. could be a wallet rather than a PDA.
+func curveKey(b byte, onCurve bool) solana.PublicKey {
+	for ; ; b++ {
+		if k := testKey(b); k.IsOnCurve() == onCurve {
+			return k
+		}
+	}
+}
+
+func TestResolveTrader_CustodyWallet(t *testing.T) {
+	keeper, custody, pool := testPayer, curveKey(10, true), curveKey(10, false)
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	withPre := func(b rpc.TokenBalance, amount string) rpc.TokenBalance {
+		b.UiTokenAmount = &rpc.UiTokenAmount{Amount: amount, Decimals: b.UiTokenAmount.Decimals}
+		return b
+	}
+	// The pool's vaults come first; the trader neither signs nor pays.
+	post := []rpc.TokenBalance{
+		tokenBalance(1, usdc, pool, "1100", 6),
+		tokenBalance(2, testBonkMint, pool, "500", 5),
+		tokenBalance(3, usdc, custody, "0", 6),
+		tokenBalance(4, testBonkMint, custody, "500", 5),
+	}
+	pre := []rpc.TokenBalance{
+		withPre(post[0], "1000"),
+		withPre(post[1], "1000"),
+		withPre(post[2], "100"),
+		withPre(post[3], "0"),
+	}
+	p := newTestParser(t,
+		[]solana.PublicKey{keeper, testKey(1), testKey(2), testKey(3), testKey(4), JUPITER_DCA_PROGRAM_ID},
+		nil,
+		&rpc.TransactionMeta{
+			Fee:               5_000,
+			PreBalances:       []uint64{10_000_000, 0, 0, 0, 0, 1},
+			PostBalances:      []uint64{9_995_000, 0, 0, 0, 0, 1},
+			PreTokenBalances:  pre,
+			PostTokenBalances: post,
+		},
+	)
+
+	if got := p.resolveTrader(); !got.Equals(custody) {
+		t.Errorf("resolveTrader = %s, want %s", got, custody)
+	}
+	// Computed once: later changes to the meta don't move it.
+	p.txMeta.PostTokenBalances = nil
+	if got := p.resolveTrader(); !got.Equals(custody) {
+		t.Errorf("cached resolveTrader = %s, want %s", got, custody)
+	}
+}
@@ -12,3 +12,14 @@ func (p *Parser) convertRPCToSolanaInstruction(rpcInst rpc.CompiledInstruction)
 		Data:           rpcInst.Data,
 	}
 }
+
+// mintDecimals returns the decimals of mint seen in the transaction; 9 for SOL.
+func (p *Parser) mintDecimals(mint solana.PublicKey) uint8 {
+	if d, ok := p.splDecimalsMap[mint.String()]; ok {
+		return d
+	}
+	if mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+		return 9
+	}
+	return 0
+}
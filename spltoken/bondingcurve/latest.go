@@ -0,0 +1,98 @@
+package bondingcurve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	solanaswapgo "github.com/P-HOW/solana-swap-decode/solanaswap-go"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// DefaultScanLimit is how many recent transactions LatestProgress looks at when
+// no limit is given; MaxScanLimit bounds it, each one being a getTransaction call.
+const (
+	DefaultScanLimit = 100
+	MaxScanLimit     = 200
+)
+
+// ErrNoTrade is returned by LatestProgress when none of the scanned
+// transactions carries a Pump.fun trade of the mint.
+var ErrNoTrade = errors.New("no pump.fun trade found")
+
+// LatestProgress scans the most recent transactions touching mint (newest first)
+// and returns the curve state after the latest Pump.fun trade found among them.
+// It fails with ErrNoTrade when there is none; any other error is from the RPC.
+func LatestProgress(ctx context.Context, client *rpc.Client, mint solana.PublicKey, limit int) (*Progress, error) {
+	if limit <= 0 {
+		limit = DefaultScanLimit
+	}
+	limit = min(limit, MaxScanLimit)
+	sigs, err := client.GetSignaturesForAddressWithOpts(ctx, mint, &rpc.GetSignaturesForAddressOpts{
+		Limit:      &limit,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetSignaturesForAddress: %w", err)
+	}
+
+	var maxTxVer uint64 = 0
+	var txErr error
+	for _, s := range sigs {
+		if s == nil || s.Err != nil {
+			continue
+		}
+		tx, err := client.GetTransaction(ctx, s.Signature, &rpc.GetTransactionOpts{
+			Commitment:                     rpc.CommitmentConfirmed,
+			MaxSupportedTransactionVersion: &maxTxVer,
+		})
+		if err != nil && !errors.Is(err, rpc.ErrNotFound) && txErr == nil {
+			txErr = fmt.Errorf("GetTransaction %s: %w", s.Signature, err)
+		}
+		if err != nil || tx == nil {
+			continue
+		}
+		pr, ok := progressInTx(tx, mint)
+		if !ok {
+			continue
+		}
+		pr.Signature = s.Signature
+		pr.Slot = tx.Slot
+		if tx.BlockTime != nil {
+			pr.BlockTime = int64(*tx.BlockTime)
+		}
+		return pr, nil
+	}
+	// a trade may sit in a transaction that couldn't be fetched
+	if txErr != nil {
+		return nil, txErr
+	}
+	return nil, fmt.Errorf("%w for %s in the last %d transactions", ErrNoTrade, mint, len(sigs))
+}
+
+// progressInTx returns the curve state after the last trade of mint in tx. The
+// curve's TradeEvents are read directly, so trades routed through aggregators
+// and bots count too.
+func progressInTx(tx *rpc.GetTransactionResult, mint solana.PublicKey) (*Progress, bool) {
+	parser, err := solanaswapgo.NewTransactionParser(tx)
+	if err != nil {
+		return nil, false
+	}
+	return lastProgress(parser, mint)
+}
+
+func lastProgress(parser *solanaswapgo.Parser, mint solana.PublicKey) (*Progress, bool) {
+	var last *solanaswapgo.PumpfunTradeEvent
+	for _, ev := range parser.PumpfunTradeEvents() {
+		if ev.Mint.Equals(mint) {
+			last = ev
+		}
+	}
+	if last == nil {
+		return nil, false
+	}
+	pr := FromTrade(last)
+	return &pr, true
+}
@@ -0,0 +1,46 @@
+package bondingcurve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// TestLatestProgress_Errors checks that only an empty scan is ErrNoTrade: a
+// transaction the node failed to return is an RPC error.
+func TestLatestProgress_Errors(t *testing.T) {
+	sig := solana.Signature{1}
+	for _, tc := range []struct {
+		name        string
+		txResponse  string
+		wantNoTrade bool
+	}{
+		{"not found", `"result":null`, true},
+		{"rpc error", `"error":{"code":-32005,"message":"node is behind"}`, false},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			body := tc.txResponse
+			if req.Method == "getSignaturesForAddress" {
+				body = `"result":[{"signature":"` + sig.String() + `","slot":1,"err":null}]`
+			}
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,` + body + `}`))
+		}))
+
+		_, err := LatestProgress(context.Background(), rpc.New(srv.URL), solana.PublicKey{2}, 1)
+		srv.Close()
+		if err == nil || errors.Is(err, ErrNoTrade) != tc.wantNoTrade {
+			t.Errorf("%s: err = %v", tc.name, err)
+		}
+	}
+}
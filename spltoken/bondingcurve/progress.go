@@ -0,0 +1,82 @@
+package bondingcurve
+
+import (
+	"math/big"
+
+	solanaswapgo "github.com/P-HOW/solana-swap-decode/solanaswap-go"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Pump.fun curve constants (raw units: lamports, token base units).
+const (
+	TokenDecimals = 6
+	TotalSupply   = 1_000_000_000_000_000 // 1B tokens
+
+	InitialVirtualSolReserves   = 30_000_000_000
+	InitialVirtualTokenReserves = 1_073_000_000_000_000
+	InitialRealTokenReserves    = 793_100_000_000_000
+
+	// virtualTokenOffset is the part of the virtual token reserves that is never
+	// sold; the real reserves are the rest.
+	virtualTokenOffset = InitialVirtualTokenReserves - InitialRealTokenReserves
+)
+
+// Progress is the state of a Pump.fun bonding curve after one trade.
+type Progress struct {
+	Mint      solana.PublicKey
+	Signature solana.Signature
+	Slot      uint64
+	BlockTime int64 // unix seconds, 0 if unknown
+
+	VirtualSolReserves   uint64
+	VirtualTokenReserves uint64
+	RealTokenReserves    uint64
+
+	// PriceSOL is SOL per whole token implied by the virtual reserves.
+	PriceSOL float64
+	// MarketCapSOL is PriceSOL × total supply.
+	MarketCapSOL float64
+	// CompletionPct is the share of the curve's sellable tokens already bought (0-100).
+	CompletionPct float64
+	// Complete is set when the curve has no tokens left to sell: the token
+	// graduates and further trading happens on PumpSwap.
+	Complete bool
+	// Completed is set on the trade that emptied the curve.
+	Completed bool
+}
+
+// FromTrade derives the curve state from a TradeEvent. Real token reserves come
+// from the event; events predating the real reserve fields have them derived
+// from the virtual ones.
+func FromTrade(ev *solanaswapgo.PumpfunTradeEvent) Progress {
+	pr := Progress{
+		Mint:                 ev.Mint,
+		BlockTime:            ev.Timestamp,
+		VirtualSolReserves:   ev.VirtualSolReserves,
+		VirtualTokenReserves: ev.VirtualTokenReserves,
+	}
+	switch {
+	case ev.RealSolReserves > 0 || ev.RealTokenReserves > 0:
+		pr.RealTokenReserves = ev.RealTokenReserves
+	case ev.VirtualTokenReserves > virtualTokenOffset:
+		pr.RealTokenReserves = ev.VirtualTokenReserves - virtualTokenOffset
+	}
+
+	if ev.VirtualTokenReserves > 0 {
+		price := new(big.Rat).SetFrac(
+			new(big.Int).SetUint64(ev.VirtualSolReserves),
+			new(big.Int).SetUint64(ev.VirtualTokenReserves),
+		)
+		// lamports per base unit → SOL per token
+		price.Mul(price, big.NewRat(1_000_000, 1_000_000_000))
+		pr.PriceSOL, _ = price.Float64()
+		pr.MarketCapSOL = pr.PriceSOL * float64(TotalSupply) / 1_000_000
+	}
+
+	sold := InitialRealTokenReserves - min(pr.RealTokenReserves, InitialRealTokenReserves)
+	pr.CompletionPct = 100 * float64(sold) / float64(InitialRealTokenReserves)
+	pr.Complete = pr.RealTokenReserves == 0
+	pr.Completed = pr.Complete && ev.IsBuy
+	return pr
+}
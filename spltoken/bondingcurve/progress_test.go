@@ -0,0 +1,62 @@
+package bondingcurve
+
+import (
+	"math"
+	"testing"
+
+	solanaswapgo "github.com/P-HOW/solana-swap-decode/solanaswap-go"
+)
+
+func TestFromTrade(t *testing.T) {
+	tests := []struct {
+		name         string
+		ev           solanaswapgo.PumpfunTradeEvent
+		wantPrice    float64
+		wantPct      float64
+		wantComplete bool
+	}{
+		{
+			name:      "fresh curve",
+			ev:        solanaswapgo.PumpfunTradeEvent{IsBuy: true, VirtualSolReserves: InitialVirtualSolReserves, VirtualTokenReserves: InitialVirtualTokenReserves},
+			wantPrice: 30.0 / 1_073_000_000,
+			wantPct:   0,
+		},
+		{
+			name:      "half sold",
+			ev:        solanaswapgo.PumpfunTradeEvent{IsBuy: false, VirtualSolReserves: 55_000_000_000, VirtualTokenReserves: virtualTokenOffset + InitialRealTokenReserves/2},
+			wantPrice: 55.0 / ((virtualTokenOffset + InitialRealTokenReserves/2) / 1e6),
+			wantPct:   50,
+		},
+		{
+			// real reserves from the event take precedence over the virtual offset
+			name:      "real reserves",
+			ev:        solanaswapgo.PumpfunTradeEvent{VirtualSolReserves: 55_000_000_000, VirtualTokenReserves: virtualTokenOffset + InitialRealTokenReserves/2, RealSolReserves: 25_000_000_000, RealTokenReserves: InitialRealTokenReserves / 4},
+			wantPrice: 55.0 / ((virtualTokenOffset + InitialRealTokenReserves/2) / 1e6),
+			wantPct:   75,
+		},
+		{
+			name:         "completing buy",
+			ev:           solanaswapgo.PumpfunTradeEvent{IsBuy: true, VirtualSolReserves: 115_005_359_056, VirtualTokenReserves: virtualTokenOffset},
+			wantPrice:    115.005359056 / (virtualTokenOffset / 1e6),
+			wantPct:      100,
+			wantComplete: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := FromTrade(&tt.ev)
+			if math.Abs(pr.PriceSOL-tt.wantPrice) > 1e-15 {
+				t.Errorf("PriceSOL = %g, want %g", pr.PriceSOL, tt.wantPrice)
+			}
+			if math.Abs(pr.MarketCapSOL-tt.wantPrice*1e9) > 1e-3 {
+				t.Errorf("MarketCapSOL = %g, want %g", pr.MarketCapSOL, tt.wantPrice*1e9)
+			}
+			if math.Abs(pr.CompletionPct-tt.wantPct) > 1e-9 {
+				t.Errorf("CompletionPct = %g, want %g", pr.CompletionPct, tt.wantPct)
+			}
+			if pr.Complete != tt.wantComplete || pr.Completed != (tt.wantComplete && tt.ev.IsBuy) {
+				t.Errorf("Complete/Completed = %v/%v", pr.Complete, pr.Completed)
+			}
+		})
+	}
+}